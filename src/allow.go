@@ -0,0 +1,132 @@
+package httprouter
+
+/*
+	patternAllow
+	allowHandle
+	Router
+		updateAllowed
+		pathAllowed
+	joinMethods
+*/
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// patternAllow
+// 为同一个路径注册了路由的请求方法
+type patternAllow struct {
+	methods []string
+	allow   string //以joinMethods连接的methods
+	any     bool   //是否通过Any注册,此时允许服务器范围内的所有方法
+}
+
+// allowHandle
+// allowTree中叶子节点的handle,只用来标记注册过的路径,不会被调用
+func allowHandle(http.ResponseWriter, *http.Request, Params) {}
+
+// updateAllowed
+// 注册路由后调用,重新计算服务器范围内允许的方法,以及该路由的路径所允许的方法
+// method为空字符串表示通过Any注册的路由
+func (r *Router) updateAllowed(method, path string) {
+	methods := make([]string, 0, len(r.trees)+len(anyMethods))
+	for m := range r.trees {
+		methods = append(methods, m)
+	}
+	// 通过Any注册的路由允许所有标准的请求方法
+	if r.anyTree != nil {
+		for _, m := range anyMethods {
+			if _, ok := r.trees[m]; !ok {
+				methods = append(methods, m)
+			}
+		}
+	}
+	r.globalAllowed = joinMethods(methods)
+
+	if r.patternAllowed == nil {
+		r.patternAllowed = make(map[string]patternAllow)
+	}
+	pa, seen := r.patternAllowed[path]
+	if method == "" {
+		pa.any = true
+	} else {
+		pa.methods = append(pa.methods, method)
+		pa.allow = joinMethods(pa.methods)
+	}
+	r.patternAllowed[path] = pa
+	if seen || r.allowMixed {
+		return
+	}
+	// 把所有请求方法的路径注册到同一颗词典树中
+	// 不同的请求方法使用了互相冲突的路径时,例如GET /users/:id与POST /users/new,
+	// 放弃这颗词典树,此后pathAllowed检索每一颗词典树
+	if r.allowTree == nil {
+		r.allowTree = new(node)
+	}
+	if err := r.allowTree.addRoute(path, allowHandle); err != nil {
+		r.allowTree, r.allowMixed = nil, true
+	}
+}
+
+// pathAllowed
+// 返回为给定路径注册了处理器的请求方法,通过Any注册的路径允许服务器范围内的所有方法
+// 通常只需在allowTree中检索一次,直接使用匹配到的路径预先计算的结果;
+// 只有不同的请求方法使用了互相冲突的路径时,才检索每一颗词典树并重新连接
+func (r *Router) pathAllowed(path string) string {
+	if !r.allowMixed {
+		if r.allowTree == nil {
+			return ""
+		}
+		leaf, _, _ := r.allowTree.getNode(path, nil)
+		if leaf == nil {
+			return ""
+		}
+		if pa := r.patternAllowed[leaf.fullPath]; !pa.any {
+			return pa.allow
+		}
+		return r.globalAllowed
+	}
+	if handle, _ := r.lookupAny(path); handle != nil {
+		return r.globalAllowed
+	}
+	var methods []string
+	for method, root := range r.trees {
+		if handle, _, _ := root.getValue(path); handle != nil {
+			methods = append(methods, method)
+		}
+	}
+	return joinMethods(methods)
+}
+
+// joinMethods
+// 把请求方法按照规范的顺序以','连接,并在最后添加OPTIONS
+// 标准方法按照anyMethods中的顺序排列,其他方法按照字母顺序排在其后
+// 没有除OPTIONS以外的方法时返回空字符串
+func joinMethods(methods []string) string {
+	rank := func(method string) int {
+		for i, m := range anyMethods {
+			if m == method {
+				return i
+			}
+		}
+		return len(anyMethods)
+	}
+	sorted := make([]string, 0, len(methods)+1)
+	for _, method := range methods {
+		if method != "OPTIONS" {
+			sorted = append(sorted, method)
+		}
+	}
+	if len(sorted) == 0 {
+		return ""
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		ri, rj := rank(sorted[i]), rank(sorted[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return sorted[i] < sorted[j]
+	})
+	return strings.Join(append(sorted, "OPTIONS"), ",")
+}
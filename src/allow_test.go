@@ -0,0 +1,99 @@
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAllowHeader(t *testing.T) {
+	r := New()
+	r.GET("/users/:id", fakeHandle)
+	r.PUT("/users/:id", fakeHandle)
+	r.DELETE("/users/:id", fakeHandle)
+	r.POST("/users/new", fakeHandle) // 与GET /users/:id不在同一颗词典树中
+	r.Handle("PURGE", "/cache", fakeHandle)
+	r.Handle("LINK", "/cache", fakeHandle)
+	r.Any("/rpc", fakeHandle)
+
+	cases := []struct{ method, path, allow string }{
+		{"POST", "/users/42", "GET,PUT,DELETE,OPTIONS"},
+		{"OPTIONS", "/users/42", "GET,PUT,DELETE,OPTIONS"},
+		{"PATCH", "/users/new", "GET,POST,PUT,DELETE,OPTIONS"},
+		{"GET", "/cache", "LINK,PURGE,OPTIONS"},
+		{"OPTIONS", "/rpc", "GET,HEAD,POST,PUT,PATCH,DELETE,CONNECT,TRACE,LINK,PURGE,OPTIONS"},
+		{"OPTIONS", "*", "GET,HEAD,POST,PUT,PATCH,DELETE,CONNECT,TRACE,LINK,PURGE,OPTIONS"},
+	}
+	for _, c := range cases {
+		if c.method == "OPTIONS" && c.path == "/rpc" {
+			// OPTIONS请求交给通过Any注册的路由,只检查计算结果
+			if got := r.allowed(c.path); got != c.allow {
+				t.Errorf("allowed(%q) = %q, want %q", c.path, got, c.allow)
+			}
+			continue
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(c.method, c.path, nil))
+		if got := w.Header().Get("Allow"); got != c.allow {
+			t.Errorf("%s %s: Allow = %q, want %q", c.method, c.path, got, c.allow)
+		}
+		if c.method != "OPTIONS" && w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s: status %d, want 405", c.method, c.path, w.Code)
+		}
+	}
+
+	// HandleHEAD时GET的路由同时允许HEAD
+	r.HandleHEAD = true
+	if got := r.allowed("/users/42"); got != "GET,HEAD,PUT,DELETE,OPTIONS" {
+		t.Errorf("with HandleHEAD: allowed = %q", got)
+	}
+
+	// 注册新的路由后结果随之更新
+	r.PATCH("/users/:id", fakeHandle)
+	if got := r.allowed("/users/42"); got != "GET,HEAD,PUT,PATCH,DELETE,OPTIONS" {
+		t.Errorf("after PATCH: allowed = %q", got)
+	}
+}
+
+func TestAllowHeaderSingleTree(t *testing.T) {
+	r := New()
+	r.GET("/users/:id", fakeHandle)
+	r.PUT("/users/:id", fakeHandle)
+	r.GET("/users/:id/posts", fakeHandle)
+	r.POST("/users/:id/posts", fakeHandle)
+	r.Handle("PURGE", "/cache", fakeHandle)
+	r.GET("/static/*filepath", fakeHandle)
+	r.HEAD("/static/*filepath", fakeHandle)
+	r.Any("/rpc", fakeHandle)
+	r.POST("/rpc", fakeHandle)
+	if r.allowMixed {
+		t.Fatal("routes without conflicting paths fell back to walking every tree")
+	}
+
+	cases := []struct{ path, allow string }{
+		{"/users/42", "GET,PUT,OPTIONS"},
+		{"/users/42/posts", "GET,POST,OPTIONS"},
+		{"/users/42/", ""},
+		{"/cache", "PURGE,OPTIONS"},
+		{"/static/app.js", "GET,HEAD,OPTIONS"},
+		{"/rpc", "GET,HEAD,POST,PUT,PATCH,DELETE,CONNECT,TRACE,PURGE,OPTIONS"},
+		{"/nope", ""},
+	}
+	check := func(when string) {
+		t.Helper()
+		for _, c := range cases {
+			if got := r.allowed(c.path); got != c.allow {
+				t.Errorf("%s: allowed(%q) = %q, want %q", when, c.path, got, c.allow)
+			}
+		}
+	}
+	check("single tree")
+
+	// 互相冲突的路径只能逐一检索每一颗词典树,结果保持不变
+	r.DELETE("/users/new", fakeHandle)
+	if !r.allowMixed {
+		t.Fatal("conflicting paths were added to the single tree")
+	}
+	cases = append(cases, struct{ path, allow string }{"/users/new", "GET,PUT,DELETE,OPTIONS"})
+	check("mixed paths")
+}
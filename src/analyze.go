@@ -0,0 +1,290 @@
+package httprouter
+
+/*
+	FindingKind
+		String
+	RouteSpec
+	Finding
+		String
+	Analyze
+	AnalyzeRoutes
+	analyzer
+		run
+		trailingSlash
+		caseCollision
+		find
+	samplePath
+	methodName
+*/
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// 分析结果的类型
+const (
+	FindingConflict      FindingKind = iota //路由无法注册
+	FindingTrailingSlash                    //添加或者去掉'/'的请求没有按照预期重定向
+	FindingCaseCollision                    //只有大小写不同的请求被RedirectFixedPath重定向到另一个路由
+)
+
+// FindingKind
+// 一条分析结果的类型
+type FindingKind uint8
+
+// String
+// 返回类型的简短描述
+func (k FindingKind) String() string {
+	switch k {
+	case FindingConflict:
+		return "conflict"
+	case FindingTrailingSlash:
+		return "trailing-slash"
+	case FindingCaseCollision:
+		return "case-collision"
+	}
+	return "unknown"
+}
+
+// RouteSpec
+// 一条待分析的路由,Method为空字符串表示通过Any注册
+type RouteSpec struct {
+	Method string
+	Path   string
+}
+
+// Finding
+// 一条分析结果
+type Finding struct {
+	Kind   FindingKind
+	Method string //路由的请求方法,通过Any注册的路由为空字符串
+	Path   string //分析结果所针对的路由
+	Other  string //与之相关的另一个路由,没有时为空字符串
+	Index  int    //路由在AnalyzeRoutes的参数中的位置,Analyze的结果中为-1
+
+	// Other的请求方法,通过Any注册的路由为空字符串
+	// 同一个路径为多个其他方法注册时,为其中字典序最小的方法
+	OtherMethod string
+
+	// 冲突的详细信息,仅对FindingConflict有效
+	Err *RouteConflictError
+
+	// 可读的说明
+	Explanation string
+}
+
+// String
+// 返回"kind METHOD /path: explanation"格式的描述
+func (f Finding) String() string {
+	return f.Kind.String() + " " + methodName(f.Method) + " " + f.Path + ": " + f.Explanation
+}
+
+// Analyze
+// 检查已经注册到Router的路由,报告以下问题:
+//
+//	1.添加或者去掉'/'后的请求被其他路由处理,因此不会被重定向到该路由;
+//	  或者添加或者去掉'/'后的路径只为其他方法注册,请求会被重定向而不是得到405
+//	2.只有大小写不同的请求被RedirectFixedPath重定向到另一个路由
+//
+// 只报告在Router当前的选项下会发生的问题,例如RedirectFixedPath为false时不报告第2类问题
+// 已经注册的路由之间不会有冲突,需要检查冲突时使用AnalyzeRoutes
+func Analyze(r *Router) []Finding {
+	a := &analyzer{router: r}
+	return a.run()
+}
+
+// AnalyzeRoutes
+// 在一个使用默认选项的新Router上依次注册给定的路由,
+// 报告无法注册的路由,以及Analyze对注册成功的路由所报告的问题
+// 无法注册的路由会被跳过,不影响后面的路由;结果中的Index为路由在routes中的位置
+func AnalyzeRoutes(routes []RouteSpec) []Finding {
+	a := &analyzer{router: New(), index: make(map[string]int)}
+	var findings []Finding
+	for i, route := range routes {
+		var err error
+		if route.Method == "" {
+			err = a.router.TryAny(route.Path, analyzeHandle)
+		} else {
+			err = a.router.TryHandle(route.Method, route.Path, analyzeHandle)
+		}
+		var ce *RouteConflictError
+		if !errors.As(err, &ce) {
+			a.index[route.Method+" "+route.Path] = i
+			continue
+		}
+		f := Finding{
+			Kind:        FindingConflict,
+			Method:      route.Method,
+			Path:        route.Path,
+			Other:       ce.Existing,
+			OtherMethod: route.Method,
+			Index:       i,
+			Err:         ce,
+			Explanation: ce.Reason.String() + ": " + ce.Error(),
+		}
+		if ce.Existing != "" {
+			f.Explanation = fmt.Sprintf("%s with %s %s: %s",
+				ce.Reason, methodName(route.Method), ce.Existing, ce.Error())
+		}
+		findings = append(findings, f)
+	}
+	return append(findings, a.run()...)
+}
+
+// analyzeHandle
+// AnalyzeRoutes注册路由时使用的handle
+func analyzeHandle(http.ResponseWriter, *http.Request, Params) {}
+
+// analyzer
+// 对一个Router的路由进行分析
+type analyzer struct {
+	router *Router
+
+	// 以"METHOD /path"为键的路由在AnalyzeRoutes的参数中的位置,为空时Index总是-1
+	index map[string]int
+}
+
+// run
+// 按照请求方法的字典序检查每一颗词典树中的路由
+// 通过Any注册的路由不会被重定向,只作为其他路由的对照
+func (a *analyzer) run() []Finding {
+	methods := make([]string, 0, len(a.router.trees))
+	for method := range a.router.trees {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	var findings []Finding
+	for _, method := range methods {
+		root := a.router.trees[method]
+		root.eachRoute(func(n *node) {
+			if a.router.RedirectTrailingSlash {
+				if f, ok := a.trailingSlash(method, root, n.fullPath); ok {
+					findings = append(findings, f)
+				}
+			}
+			if a.router.RedirectFixedPath {
+				if f, ok := a.caseCollision(method, root, n.fullPath); ok {
+					findings = append(findings, f)
+				}
+			}
+		})
+	}
+	return findings
+}
+
+// trailingSlash
+// 检查添加或者去掉'/'后的请求是否会被重定向到该路由
+func (a *analyzer) trailingSlash(method string, root *node, path string) (Finding, bool) {
+	if path == "/" || strings.Contains(path, "/*") {
+		// 全匹配参数同时匹配两种写法
+		return Finding{}, false
+	}
+	sample := samplePath(path)
+	toggled := sample + "/"
+	if strings.HasSuffix(sample, "/") {
+		toggled = sample[:len(sample)-1]
+	}
+	f := Finding{Kind: FindingTrailingSlash, Method: method, Path: path, Index: a.find(method, path)}
+	if leaf, _, _ := root.getNode(toggled, nil); leaf != nil {
+		if samplePath(leaf.fullPath) == toggled {
+			// 两种写法分别注册了路由
+			return Finding{}, false
+		}
+		f.Other, f.OtherMethod = leaf.fullPath, method
+		f.Explanation = fmt.Sprintf("%s %s is handled by %s instead of being redirected to %s",
+			method, toggled, leaf.fullPath, path)
+		return f, true
+	}
+	if _, _, tsr := root.getNode(toggled, nil); !tsr {
+		return Finding{}, false
+	}
+	if a.router.anyTree != nil {
+		if leaf, _, _ := a.router.anyTree.getNode(toggled, nil); leaf != nil {
+			f.Other = leaf.fullPath
+			f.Explanation = fmt.Sprintf("%s %s is handled by ANY %s instead of being redirected to %s",
+				method, toggled, leaf.fullPath, path)
+			return f, true
+		}
+	}
+	var others []string
+	routes := make(map[string]string)
+	for other, tree := range a.router.trees {
+		if other == method {
+			continue
+		}
+		if leaf, _, _ := tree.getNode(toggled, nil); leaf != nil {
+			others = append(others, other)
+			routes[other] = leaf.fullPath
+		}
+	}
+	if len(others) == 0 {
+		return Finding{}, false
+	}
+	sort.Strings(others)
+	f.Other, f.OtherMethod = routes[others[0]], others[0]
+	f.Explanation = fmt.Sprintf("%s %s is redirected to %s although %s is registered for %s; clients get a redirect instead of 405",
+		method, toggled, path, f.Other, strings.Join(others, ", "))
+	return f, true
+}
+
+// caseCollision
+// 检查与该路由只有大小写不同的请求是否会被RedirectFixedPath重定向到另一个路由
+func (a *analyzer) caseCollision(method string, root *node, path string) (Finding, bool) {
+	sample := samplePath(path)
+	upper := strings.ToUpper(sample)
+	if upper == sample {
+		return Finding{}, false
+	}
+	fixed, found := root.findCaseInsensitivePath(upper, a.router.RedirectTrailingSlash)
+	if !found {
+		return Finding{}, false
+	}
+	leaf, _, _ := root.getNode(string(fixed), nil)
+	if leaf == nil || leaf.fullPath == path {
+		return Finding{}, false
+	}
+	return Finding{
+		Kind:        FindingCaseCollision,
+		Method:      method,
+		Path:        path,
+		Other:       leaf.fullPath,
+		OtherMethod: method,
+		Index:       a.find(method, path),
+		Explanation: fmt.Sprintf("RedirectFixedPath redirects %s %s to %s, handled by %s instead of %s",
+			method, upper, fixed, leaf.fullPath, path),
+	}, true
+}
+
+// find
+// 返回路由在AnalyzeRoutes的参数中的位置,没有时返回-1
+func (a *analyzer) find(method, path string) int {
+	if i, ok := a.index[method+" "+path]; ok {
+		return i
+	}
+	return -1
+}
+
+// samplePath
+// 把路由中的参数替换为参数名,得到一个可以匹配该路由的请求路径
+// 例如"/users/:id/*rest"对应"/users/id/rest"
+func samplePath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if c := path[i]; c != ':' && c != '*' {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// methodName
+// 返回用于显示的请求方法,通过Any注册的路由显示为"ANY"
+func methodName(method string) string {
+	if method == "" {
+		return "ANY"
+	}
+	return method
+}
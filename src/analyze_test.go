@@ -0,0 +1,130 @@
+package httprouter
+
+import (
+	"reflect"
+	"testing"
+)
+
+// findingKey
+// Finding中测试关心的字段
+type findingKey struct {
+	kind        FindingKind
+	method      string
+	path        string
+	other       string
+	otherMethod string
+	index       int
+}
+
+// findingKeys
+// 提取每一条Finding中测试关心的字段
+func findingKeys(findings []Finding) []findingKey {
+	var keys []findingKey
+	for _, f := range findings {
+		keys = append(keys, findingKey{f.Kind, f.Method, f.Path, f.Other, f.OtherMethod, f.Index})
+	}
+	return keys
+}
+
+func TestAnalyzeRoutes(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		routes []RouteSpec
+		want   []findingKey
+	}{
+		{
+			name:   "wildcard conflict",
+			routes: []RouteSpec{{"GET", "/users/:id"}, {"GET", "/users/:name"}, {"GET", "/users/:id/posts"}},
+			want:   []findingKey{{FindingConflict, "GET", "/users/:name", "/users/:id", "GET", 1}},
+		},
+		{
+			name:   "duplicate",
+			routes: []RouteSpec{{"GET", "/a"}, {"POST", "/a"}, {"GET", "/a"}},
+			want:   []findingKey{{FindingConflict, "GET", "/a", "/a", "GET", 2}},
+		},
+		{
+			name:   "handled by another route",
+			routes: []RouteSpec{{"GET", "/users/:id"}, {"GET", "/users/:id/*rest"}},
+			want:   []findingKey{{FindingTrailingSlash, "GET", "/users/:id", "/users/:id/*rest", "GET", 0}},
+		},
+		{
+			name:   "redirect instead of 405",
+			routes: []RouteSpec{{"GET", "/items"}, {"POST", "/items/"}},
+			want: []findingKey{
+				{FindingTrailingSlash, "GET", "/items", "/items/", "POST", 0},
+				{FindingTrailingSlash, "POST", "/items/", "/items", "GET", 1},
+			},
+		},
+		{
+			name:   "redirect instead of 405 for several methods",
+			routes: []RouteSpec{{"GET", "/items"}, {"PUT", "/items/"}, {"DELETE", "/items/"}},
+			want: []findingKey{
+				{FindingTrailingSlash, "DELETE", "/items/", "/items", "GET", 2},
+				{FindingTrailingSlash, "GET", "/items", "/items/", "DELETE", 0},
+				{FindingTrailingSlash, "PUT", "/items/", "/items", "GET", 1},
+			},
+		},
+		{
+			name:   "handled by Any",
+			routes: []RouteSpec{{"GET", "/rpc/"}, {"", "/rpc"}},
+			want:   []findingKey{{FindingTrailingSlash, "GET", "/rpc/", "/rpc", "", 0}},
+		},
+		{
+			name:   "case collision",
+			routes: []RouteSpec{{"GET", "/users"}, {"GET", "/Users"}},
+			want:   []findingKey{{FindingCaseCollision, "GET", "/Users", "/users", "GET", 1}},
+		},
+		{
+			name:   "both slash variants registered",
+			routes: []RouteSpec{{"GET", "/docs"}, {"GET", "/docs/"}, {"GET", "/users/:id"}, {"GET", "/users/:id/"}},
+		},
+		{
+			name:   "catch-all matches both variants",
+			routes: []RouteSpec{{"GET", "/src/*filepath"}, {"POST", "/src/*filepath"}},
+		},
+	} {
+		findings := AnalyzeRoutes(c.routes)
+		if got := findingKeys(findings); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: findings\n%v\nwant\n%v", c.name, got, c.want)
+		}
+		for _, f := range findings {
+			if (f.Kind == FindingConflict) != (f.Err != nil) {
+				t.Errorf("%s: %v: Err is %v", c.name, f, f.Err)
+			}
+			if f.Explanation == "" {
+				t.Errorf("%s: %v: empty explanation", c.name, f)
+			}
+		}
+	}
+}
+
+func TestAnalyzeOptions(t *testing.T) {
+	r := New()
+	r.GET("/items", fakeHandle)
+	r.POST("/items/", fakeHandle)
+	r.GET("/Users", fakeHandle)
+	r.GET("/users", fakeHandle)
+
+	kinds := func() map[FindingKind]int {
+		n := make(map[FindingKind]int)
+		for _, f := range Analyze(r) {
+			if f.Index != -1 {
+				t.Errorf("%v: Index %d, want -1", f, f.Index)
+			}
+			n[f.Kind]++
+		}
+		return n
+	}
+	if got := kinds(); got[FindingTrailingSlash] != 2 || got[FindingCaseCollision] != 1 {
+		t.Errorf("default options: %v", got)
+	}
+	// 只报告在当前选项下会发生的问题
+	r.RedirectFixedPath = false
+	if got := kinds(); got[FindingTrailingSlash] != 2 || got[FindingCaseCollision] != 0 {
+		t.Errorf("without RedirectFixedPath: %v", got)
+	}
+	r.RedirectTrailingSlash = false
+	if got := kinds(); len(got) != 0 {
+		t.Errorf("without redirects: %v", got)
+	}
+}
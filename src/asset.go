@@ -0,0 +1,113 @@
+package httprouter
+
+/*
+	Fingerprint
+	assetManifest
+		build
+		walk
+	fingerprintName
+	Router
+		AssetURL
+*/
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"path"
+	"strings"
+)
+
+// Fingerprint
+// 在注册时计算根目录下每个文件的哈希值,生成带有指纹的文件名,例如"app.js"对应"app.3f9a1c2b.js"
+// 原始文件名与带有指纹的文件名都可以被请求,后者总是被永久缓存
+// 通过Router.AssetURL获取带有指纹的URL,用于在页面中引用文件以破坏缓存
+// 注册时读取文件失败会触发宕机
+func Fingerprint() FileOption {
+	return func(s *fileServer) {
+		s.manifest = &assetManifest{}
+	}
+}
+
+// assetManifest
+// 一组通过ServeFiles或ServeFS注册的带有指纹的文件
+type assetManifest struct {
+	prefix string            //全匹配参数之前的URL前缀,以'/'结尾
+	urls   map[string]string //原始文件名到带有指纹的文件名,都是相对于根目录的路径
+	plain  map[string]string //带有指纹的文件名到原始文件名
+}
+
+// build
+// 遍历文件系统并计算所有文件的指纹
+func (m *assetManifest) build(s *fileServer, prefix string) {
+	m.prefix = prefix
+	m.urls = make(map[string]string)
+	m.plain = make(map[string]string)
+	if err := m.walk(s, "/"); err != nil {
+		panic("failed to fingerprint files under '" + prefix + "': " + err.Error())
+	}
+}
+
+// walk
+// 递归地计算目录dir下所有文件的指纹
+func (m *assetManifest) walk(s *fileServer, dir string) error {
+	f, err := s.Open(dir)
+	if err != nil {
+		return err
+	}
+	entries, err := f.Readdir(-1)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	for _, d := range entries {
+		name := path.Join(dir, d.Name())
+		if s.blockDotfiles && strings.HasPrefix(d.Name(), ".") {
+			continue
+		}
+		if d.IsDir() {
+			if err := m.walk(s, name); err != nil {
+				return err
+			}
+			continue
+		}
+		f, err := s.Open(name)
+		if err != nil {
+			return err
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		hashed := fingerprintName(name, hex.EncodeToString(h.Sum(nil)[:4]))
+		m.urls[name] = hashed
+		m.plain[hashed] = name
+	}
+	return nil
+}
+
+// fingerprintName
+// 把哈希值插入到文件扩展名之前,没有扩展名时添加到文件名末尾
+func fingerprintName(name, hash string) string {
+	dir, file := path.Split(name)
+	if i := strings.LastIndexByte(file, '.'); i > 0 {
+		return dir + file[:i] + "." + hash + file[i:]
+	}
+	return dir + file + "." + hash
+}
+
+// AssetURL
+// 返回通过Fingerprint注册的文件带有指纹的URL,name为相对于根目录的路径,例如:
+// 		router.ServeFS("/static/*path", assets, httprouter.Fingerprint())
+// 		router.AssetURL("app.js") // "/static/app.3f9a1c2b.js"
+// 文件不存在时返回空字符串
+func (r *Router) AssetURL(name string) string {
+	name = path.Clean("/" + name)
+	for _, m := range r.assets {
+		if hashed, ok := m.urls[name]; ok {
+			return m.prefix + hashed[1:]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package httprouter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+// fingerprint
+// 返回Fingerprint为给定内容生成的指纹
+func fingerprint(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:4])
+}
+
+func TestFingerprint(t *testing.T) {
+	static := fstest.MapFS{
+		"app.js":       {Data: []byte("console.log(1)")},
+		"css/site.css": {Data: []byte("body{}")},
+		"LICENSE":      {Data: []byte("MIT")},
+		".env":         {Data: []byte("SECRET=1")},
+	}
+	media := fstest.MapFS{
+		"logo.svg": {Data: []byte("<svg></svg>")},
+	}
+	r := New()
+	r.ServeFS("/static/*path", static, Fingerprint(), BlockDotfiles())
+	r.ServeFS("/media/*file", media, Fingerprint())
+
+	appURL := "/static/app." + fingerprint("console.log(1)") + ".js"
+	for _, c := range []struct{ name, url string }{
+		{"app.js", appURL},
+		{"/app.js", appURL},
+		{"css/site.css", "/static/css/site." + fingerprint("body{}") + ".css"},
+		{"LICENSE", "/static/LICENSE." + fingerprint("MIT")},
+		{"logo.svg", "/media/logo." + fingerprint("<svg></svg>") + ".svg"},
+		{".env", ""},
+		{"missing.js", ""},
+	} {
+		if got := r.AssetURL(c.name); got != c.url {
+			t.Errorf("AssetURL(%q) = %q, want %q", c.name, got, c.url)
+		}
+	}
+
+	for _, c := range []struct {
+		path   string
+		status int
+		body   string
+		cache  string
+	}{
+		{appURL, http.StatusOK, "console.log(1)", immutableCacheControl},
+		{"/static/app.js", http.StatusOK, "console.log(1)", ""},
+		{"/static/LICENSE." + fingerprint("MIT"), http.StatusOK, "MIT", immutableCacheControl},
+		{"/static/app.00000000.js", http.StatusNotFound, "", ""},
+		{"/media/logo." + fingerprint("<svg></svg>") + ".svg", http.StatusOK, "<svg></svg>", immutableCacheControl},
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", c.path, nil))
+		if w.Code != c.status {
+			t.Errorf("GET %s: status %d, want %d", c.path, w.Code, c.status)
+			continue
+		}
+		if c.status != http.StatusOK {
+			continue
+		}
+		if w.Body.String() != c.body {
+			t.Errorf("GET %s: body %q, want %q", c.path, w.Body.String(), c.body)
+		}
+		if got := w.Header().Get("Cache-Control"); got != c.cache {
+			t.Errorf("GET %s: Cache-Control %q, want %q", c.path, got, c.cache)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+// httprouter-check
+// 在不启动服务的情况下检查路由表,适合在CI中使用
+// 用法:
+// 		httprouter-check [-strict] [-match] routes.txt
+// 路由文件每行一条"METHOD /pattern",空行与以'#'开头的行被忽略,ANY或者'*'表示通过Any注册;
+// 也可以是一个JSON数组,例如[{"method": "GET", "path": "/users/:id"}]
+// 发现冲突时以"文件:行号: 说明"的格式输出并以状态码1退出,
+// 其他问题(尾部'/'的歧义以及大小写冲突)只输出警告,使用-strict时同样以状态码1退出
+// 使用-match时从标准输入中每行读取一个"[METHOD] /path"格式的请求,输出匹配的结果:
+// 		GET /users/42 -> GET /users/:id (line 3) id=42
+// 		GET /users/42/ -> 301 /users/42 (trailing slash redirect)
+// 		GET /Users/42 -> 301 /users/42 (fixed path redirect)
+// 		POST /users/42 -> 405 method not allowed (Allow: GET, OPTIONS)
+package main
+
+/*
+	route
+	main
+	run
+	readRoutes
+		readText
+		readJSON
+	match
+*/
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	httprouter "github.com/maerwen/go-httprouter/src"
+)
+
+// route
+// 路由文件中的一条路由以及它所在的行号
+type route struct {
+	httprouter.RouteSpec
+	line int
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run
+// 执行检查并返回退出状态码:0表示通过,1表示发现冲突(或者-strict时的警告),2表示用法或者输入错误
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("httprouter-check", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	strict := flags.Bool("strict", false, "exit with status 1 on warnings as well as conflicts")
+	matchPaths := flags.Bool("match", false, "read \"[METHOD] /path\" lines from stdin and print how each is routed")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: httprouter-check [-strict] [-match] routes-file\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	name := flags.Arg(0)
+	data, err := os.ReadFile(name)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	routes, err := readRoutes(data)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s:%v\n", name, err)
+		return 2
+	}
+
+	specs := make([]httprouter.RouteSpec, len(routes))
+	for i := range routes {
+		specs[i] = routes[i].RouteSpec
+	}
+	lineOf := make(map[string]int, len(routes))
+	for _, rt := range routes {
+		key := rt.Method + " " + rt.Path
+		if _, ok := lineOf[key]; !ok {
+			lineOf[key] = rt.line
+		}
+	}
+	failed := false
+	for _, f := range httprouter.AnalyzeRoutes(specs) {
+		line := 0
+		if f.Index >= 0 {
+			line = routes[f.Index].line
+		}
+		msg := f.Explanation
+		if f.Other != "" {
+			// Other可能是为其他请求方法注册的路由,例如只为POST注册的"/items/"
+			if l, ok := lineOf[f.OtherMethod+" "+f.Other]; ok {
+				msg += fmt.Sprintf(" (see line %d)", l)
+			}
+		}
+		level := "warning"
+		if f.Kind == httprouter.FindingConflict {
+			level = "error"
+			failed = true
+		} else if *strict {
+			failed = true
+		}
+		fmt.Fprintf(stdout, "%s:%d: %s: %s\n", name, line, level, msg)
+	}
+
+	if *matchPaths {
+		router := httprouter.New()
+		for _, rt := range routes {
+			method := rt.Method
+			if method == "" {
+				method = "ANY"
+			}
+			desc := fmt.Sprintf("%s %s (line %d)", method, rt.Path, rt.line)
+			handle := func(w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
+				out := desc
+				for _, p := range ps {
+					out += " " + p.Key + "=" + p.Value
+				}
+				io.WriteString(w, out)
+			}
+			if rt.Method == "" {
+				router.TryAny(rt.Path, handle)
+			} else {
+				router.TryHandle(rt.Method, rt.Path, handle)
+			}
+		}
+		if err := match(router, stdin, stdout); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// readRoutes
+// 解析路由文件,以'['开头的文件按照JSON解析
+func readRoutes(data []byte) ([]route, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		return readJSON(data)
+	}
+	return readText(data)
+}
+
+// readText
+// 解析每行一条"METHOD /pattern"格式的路由文件
+func readText(data []byte) ([]route, error) {
+	var routes []route
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%d: expected \"METHOD /pattern\", got %q", line, text)
+		}
+		method := fields[0]
+		if method == "ANY" || method == "*" {
+			method = ""
+		}
+		routes = append(routes, route{httprouter.RouteSpec{Method: method, Path: fields[1]}, line})
+	}
+	return routes, sc.Err()
+}
+
+// readJSON
+// 解析JSON数组格式的路由文件,行号为每个元素开始的位置
+func readJSON(data []byte) ([]route, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	lineAt := func(offset int64) int {
+		return bytes.Count(data[:offset], []byte("\n")) + 1
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("%d: %v", lineAt(dec.InputOffset()), err)
+	}
+	var routes []route
+	for dec.More() {
+		// InputOffset指向上一个分隔符之后,跳过空白得到元素开始的位置
+		offset := dec.InputOffset()
+		for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,", data[offset]) >= 0 {
+			offset++
+		}
+		var v struct {
+			Method string `json:"method"`
+			Path   string `json:"path"`
+		}
+		if err := dec.Decode(&v); err != nil {
+			return nil, fmt.Errorf("%d: %v", lineAt(offset), err)
+		}
+		if v.Method == "ANY" || v.Method == "*" {
+			v.Method = ""
+		}
+		routes = append(routes, route{httprouter.RouteSpec{Method: v.Method, Path: v.Path}, lineAt(offset)})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("%d: %v", lineAt(dec.InputOffset()), err)
+	}
+	return routes, nil
+}
+
+// match
+// 从in中每行读取一个"[METHOD] /path"格式的请求,交给router处理并输出结果
+// 没有指定方法时使用GET
+func match(router *httprouter.Router, in io.Reader, out io.Writer) error {
+	sc := bufio.NewScanner(in)
+	for sc.Scan() {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		method, path := "GET", text
+		if fields := strings.Fields(text); len(fields) == 2 {
+			method, path = fields[0], fields[1]
+		}
+		if !strings.HasPrefix(path, "/") {
+			fmt.Fprintf(out, "%s -> invalid path\n", text)
+			continue
+		}
+		// httptest.NewRequest在无法解析的路径(例如"/users/%zz")上会宕机
+		req, err := http.NewRequest(method, path, nil)
+		if err != nil {
+			fmt.Fprintf(out, "%s -> invalid path\n", text)
+			continue
+		}
+		// 重定向时req.URL.Path会被修改
+		reqPath := req.URL.Path
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		result := strings.TrimSpace(w.Body.String())
+		switch code := w.Code; {
+		case code == http.StatusMovedPermanently || code == http.StatusTemporaryRedirect:
+			kind := "fixed path"
+			if _, _, tsr := router.Lookup(method, reqPath); tsr && router.RedirectTrailingSlash {
+				kind = "trailing slash"
+			}
+			result = fmt.Sprintf("%d %s (%s redirect)", code, w.Header().Get("Location"), kind)
+		case code == http.StatusMethodNotAllowed:
+			result = fmt.Sprintf("405 method not allowed (Allow: %s)", w.Header().Get("Allow"))
+		case code == http.StatusNotFound:
+			result = "404 not found"
+		case method == "OPTIONS" && result == "":
+			result = fmt.Sprintf("%d automatic OPTIONS response (Allow: %s)", code, w.Header().Get("Allow"))
+		}
+		fmt.Fprintf(out, "%s %s -> %s\n", method, path, result)
+	}
+	return sc.Err()
+}
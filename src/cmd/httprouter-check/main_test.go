@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runCheck
+// 把routes写入临时文件后执行run,返回退出状态码以及标准输出
+func runCheck(t *testing.T, routes, stdin string, args ...string) (int, string) {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "routes.txt")
+	if err := os.WriteFile(name, []byte(routes), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	code := run(append(args, name), strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String()
+}
+
+func TestRunUnreachableCatchAll(t *testing.T) {
+	code, out := runCheck(t, "GET /src/*filepath\nGET /src/*filepath/edit\n", "")
+	if code != 1 {
+		t.Errorf("exit status %d, want 1", code)
+	}
+	if !strings.Contains(out, ":2: error:") {
+		t.Errorf("conflict not reported as an error on line 2:\n%s", out)
+	}
+}
+
+func TestRunSeeLineAcrossMethods(t *testing.T) {
+	code, out := runCheck(t, "GET /items\n# comment\nPOST /items/\nGET /users\nGET /Users\n", "")
+	if code != 0 {
+		t.Errorf("exit status %d, want 0", code)
+	}
+	for _, want := range []string{
+		":1: warning: GET /items/ is redirected to /items although /items/ is registered for POST; clients get a redirect instead of 405 (see line 3)\n",
+		":3: warning: POST /items is redirected to /items/ although /items is registered for GET; clients get a redirect instead of 405 (see line 1)\n",
+		"handled by /users instead of /Users (see line 4)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunMatchInvalidPath(t *testing.T) {
+	code, out := runCheck(t, "GET /users/:id\n", "GET /users/%zz\nGET /users/42\n", "-match")
+	if code != 0 {
+		t.Errorf("exit status %d, want 0", code)
+	}
+	for _, want := range []string{
+		"GET /users/%zz -> invalid path\n",
+		"GET /users/42 -> GET /users/:id (line 1) id=42\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
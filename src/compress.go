@@ -0,0 +1,104 @@
+package httprouter
+
+/*
+	encodingExts
+	Precompressed
+	fileServer
+		openPrecompressed
+	acceptsEncoding
+	contentType
+*/
+import (
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// encodingExts
+// 预压缩文件的编码与文件扩展名的对应关系,未列出的编码使用"."+编码名称
+var encodingExts = map[string]string{
+	"br":   ".br",
+	"gzip": ".gz",
+	"zstd": ".zst",
+}
+
+// Precompressed
+// 根据请求的Accept-Encoding查找预先压缩好的同名文件,例如app.js.br与app.js.gz,
+// 找到时以对应的Content-Encoding提供,并保留原始文件的Content-Type
+// encodings按照优先级排列,如果未设置则为"br"与"gzip"
+// 没有可用的预压缩文件时提供原始文件
+func Precompressed(encodings ...string) FileOption {
+	if len(encodings) == 0 {
+		encodings = []string{"br", "gzip"}
+	}
+	return func(s *fileServer) {
+		s.encodings = encodings
+	}
+}
+
+// openPrecompressed
+// 打开客户端可以接受的、优先级最高的预压缩文件,name为原始文件相对于根目录的路径
+// 没有可用的预压缩文件时返回nil
+func (s *fileServer) openPrecompressed(req *http.Request, name string) (http.File, fs.FileInfo, string) {
+	accept := req.Header.Get("Accept-Encoding")
+	if accept == "" {
+		return nil, nil, ""
+	}
+	for _, encoding := range s.encodings {
+		if !acceptsEncoding(accept, encoding) {
+			continue
+		}
+		ext, ok := encodingExts[encoding]
+		if !ok {
+			ext = "." + encoding
+		}
+		f, err := s.Open(name + ext)
+		if err != nil {
+			continue
+		}
+		if d, err := f.Stat(); err == nil && !d.IsDir() {
+			return f, d, encoding
+		}
+		f.Close()
+	}
+	return nil, nil, ""
+}
+
+// acceptsEncoding
+// 判断Accept-Encoding请求头是否接受给定的编码,q=0表示明确拒绝
+func acceptsEncoding(accept, encoding string) bool {
+	wildcard := false
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.TrimSpace(name)
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		switch {
+		case strings.EqualFold(name, encoding):
+			return q > 0
+		case name == "*":
+			wildcard = q > 0
+		}
+	}
+	return wildcard
+}
+
+// contentType
+// 根据文件扩展名返回Content-Type,无法确定时读取文件开头的内容进行推测
+func contentType(name string, f http.File) string {
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		return ctype
+	}
+	var buf [512]byte
+	n, _ := io.ReadFull(f, buf[:])
+	f.Seek(0, io.SeekStart)
+	return http.DetectContentType(buf[:n])
+}
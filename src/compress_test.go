@@ -0,0 +1,83 @@
+package httprouter
+
+import (
+	"mime"
+	"net/http/httptest"
+	"path"
+	"testing"
+	"testing/fstest"
+)
+
+func TestPrecompressed(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.js":        {Data: []byte("plain js")},
+		"app.js.br":     {Data: []byte("br js")},
+		"app.js.gz":     {Data: []byte("gzip js")},
+		"app.js.zst":    {Data: []byte("zstd js")},
+		"style.css":     {Data: []byte("plain css")},
+		"style.css.gz":  {Data: []byte("gzip css")},
+		"logo.svg":      {Data: []byte("<svg></svg>")},
+		"logo.svg.br/x": {Data: []byte("a directory, not a file")},
+	}
+	for _, c := range []struct {
+		encodings []string
+		path      string
+		accept    string
+		encoding  string //期望的Content-Encoding,为空表示提供原始文件
+		body      string
+	}{
+		{path: "/app.js", accept: "", body: "plain js"},
+		{path: "/app.js", accept: "gzip", encoding: "gzip", body: "gzip js"},
+		{path: "/app.js", accept: "gzip, br", encoding: "br", body: "br js"},
+		{path: "/app.js", accept: "GZIP", encoding: "gzip", body: "gzip js"},
+		{path: "/app.js", accept: "br;q=0, gzip", encoding: "gzip", body: "gzip js"},
+		{path: "/app.js", accept: "br; q=0.5, gzip;q=0.1", encoding: "br", body: "br js"},
+		{path: "/app.js", accept: "*", encoding: "br", body: "br js"},
+		{path: "/app.js", accept: "*, br;q=0", encoding: "gzip", body: "gzip js"},
+		{path: "/app.js", accept: "*;q=0", body: "plain js"},
+		{path: "/app.js", accept: "identity", body: "plain js"},
+		{path: "/app.js", accept: "zstd", body: "plain js"},
+		{encodings: []string{"zstd", "gzip"}, path: "/app.js", accept: "gzip, zstd", encoding: "zstd", body: "zstd js"},
+		{path: "/style.css", accept: "br, gzip", encoding: "gzip", body: "gzip css"},
+		{path: "/logo.svg", accept: "br", body: "<svg></svg>"},
+	} {
+		r := New()
+		r.ServeFS("/*filepath", fsys, Precompressed(c.encodings...))
+		req := httptest.NewRequest("GET", c.path, nil)
+		if c.accept != "" {
+			req.Header.Set("Accept-Encoding", c.accept)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		name := c.path + " Accept-Encoding " + c.accept
+		if w.Code != 200 {
+			t.Errorf("%s: status %d", name, w.Code)
+			continue
+		}
+		if got := w.Header().Get("Content-Encoding"); got != c.encoding {
+			t.Errorf("%s: Content-Encoding %q, want %q", name, got, c.encoding)
+		}
+		if w.Body.String() != c.body {
+			t.Errorf("%s: body %q, want %q", name, w.Body.String(), c.body)
+		}
+		// 压缩后的文件仍然使用原始文件的Content-Type
+		if got, want := w.Header().Get("Content-Type"), mime.TypeByExtension(path.Ext(c.path)); got != want {
+			t.Errorf("%s: Content-Type %q, want %q", name, got, want)
+		}
+		// 即使提供的是原始文件,响应也取决于Accept-Encoding
+		if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+			t.Errorf("%s: Vary %q, want Accept-Encoding", name, got)
+		}
+	}
+
+	// 未使用Precompressed时不查找预压缩文件,也不设置Vary
+	r := New()
+	r.ServeFS("/*filepath", fsys)
+	req := httptest.NewRequest("GET", "/app.js", nil)
+	req.Header.Set("Accept-Encoding", "br")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Body.String() != "plain js" || w.Header().Get("Content-Encoding") != "" || w.Header().Get("Vary") != "" {
+		t.Errorf("without Precompressed: body %q, headers %v", w.Body.String(), w.Header())
+	}
+}
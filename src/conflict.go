@@ -0,0 +1,174 @@
+package httprouter
+
+/*
+	ConflictReason
+		String
+	RouteConflictError
+		Error
+	Router
+		TryHandle
+		TryGET
+		TryHEAD
+		TryOPTIONS
+		TryPOST
+		TryPUT
+		TryPATCH
+		TryDELETE
+		TryAny
+		tryAdd
+*/
+
+// 注册失败的原因
+const (
+	ConflictInvalid          ConflictReason = iota //请求方法或者路径不合法
+	ConflictDuplicate                              //同一路径已经注册了handle
+	ConflictWildcard                               //通配符与已有的通配符或者子节点冲突
+	ConflictWildcardSegment                        //一个路径段中有多个通配符
+	ConflictCatchAllPosition                       //全匹配参数不在路径末尾,或者前面没有'/'
+	ConflictUnnamedWildcard                        //通配符没有名字
+)
+
+// ConflictReason
+// 一条路由无法被注册的原因
+type ConflictReason uint8
+
+// String
+// 返回原因的简短描述
+func (c ConflictReason) String() string {
+	switch c {
+	case ConflictInvalid:
+		return "invalid route"
+	case ConflictDuplicate:
+		return "duplicate handle"
+	case ConflictWildcard:
+		return "wildcard conflict"
+	case ConflictWildcardSegment:
+		return "multiple wildcards in segment"
+	case ConflictCatchAllPosition:
+		return "catch-all position"
+	case ConflictUnnamedWildcard:
+		return "unnamed wildcard"
+	}
+	return "unknown conflict"
+}
+
+// RouteConflictError
+// TryHandle等方法在路由无法注册时返回的错误
+// 错误信息与Handle触发宕机时的信息相同
+type RouteConflictError struct {
+	Method   string         //请求方法,通过TryAny注册时为空字符串
+	Path     string         //新注册的路径
+	Existing string         //与之冲突的已有路由的路径,路径本身不合法时为空字符串
+	Reason   ConflictReason //冲突的原因
+
+	msg string
+}
+
+// Error
+// 实现error接口
+func (e *RouteConflictError) Error() string {
+	return e.msg
+}
+
+// TryHandle
+// 与Handle相同,但是在路由无法注册时返回一个*RouteConflictError,而不是触发宕机
+// 适合注册来自配置文件等外部输入的路由
+// 注册失败时词典树保持不变
+func (r *Router) TryHandle(method, path string, handle Handle) error {
+	if !isToken(method) {
+		return &RouteConflictError{
+			Method: method,
+			Path:   path,
+			Reason: ConflictInvalid,
+			msg:    "method must be a valid HTTP token in method '" + method + "'",
+		}
+	}
+	if r.trees == nil {
+		r.trees = make(map[string]*node)
+	}
+	root, err := r.tryAdd(r.trees[method], path, handle)
+	if err != nil {
+		err.Method = method
+		return err
+	}
+	r.trees[method] = root
+	r.updateAllowed(method, path)
+	return nil
+}
+
+//TryGET
+//快捷调用router.TryHandle("GET", path, handle)
+func (r *Router) TryGET(path string, handle Handle) error {
+	return r.TryHandle("GET", path, handle)
+}
+
+//TryHEAD
+//快捷调用router.TryHandle("HEAD", path, handle)
+func (r *Router) TryHEAD(path string, handle Handle) error {
+	return r.TryHandle("HEAD", path, handle)
+}
+
+//TryOPTIONS
+//快捷调用router.TryHandle("OPTIONS", path, handle)
+func (r *Router) TryOPTIONS(path string, handle Handle) error {
+	return r.TryHandle("OPTIONS", path, handle)
+}
+
+//TryPOST
+//快捷调用router.TryHandle("POST", path, handle)
+func (r *Router) TryPOST(path string, handle Handle) error {
+	return r.TryHandle("POST", path, handle)
+}
+
+//TryPUT
+//快捷调用router.TryHandle("PUT", path, handle)
+func (r *Router) TryPUT(path string, handle Handle) error {
+	return r.TryHandle("PUT", path, handle)
+}
+
+//TryPATCH
+//快捷调用router.TryHandle("PATCH", path, handle)
+func (r *Router) TryPATCH(path string, handle Handle) error {
+	return r.TryHandle("PATCH", path, handle)
+}
+
+//TryDELETE
+//快捷调用router.TryHandle("DELETE", path, handle)
+func (r *Router) TryDELETE(path string, handle Handle) error {
+	return r.TryHandle("DELETE", path, handle)
+}
+
+// TryAny
+// 与Any相同,但是在路由无法注册时返回一个*RouteConflictError,而不是触发宕机
+func (r *Router) TryAny(path string, handle Handle) error {
+	root, err := r.tryAdd(r.anyTree, path, handle)
+	if err != nil {
+		return err
+	}
+	r.anyTree = root
+	r.updateAllowed("", path)
+	return nil
+}
+
+// tryAdd
+// 在词典树的副本上注册路由,成功时返回新的词典树
+// addRoute在发现冲突前可能已经修改了词典树,因此不能直接在原词典树上注册
+// 只有注册时经过的节点被复制,其余节点与原词典树共享
+func (r *Router) tryAdd(root *node, path string, handle Handle) (*node, *RouteConflictError) {
+	if path == "" || path[0] != '/' {
+		return nil, &RouteConflictError{
+			Path:   path,
+			Reason: ConflictInvalid,
+			msg:    "path must begin with '/' in path '" + path + "'",
+		}
+	}
+	if root == nil {
+		root = new(node)
+	} else {
+		root = root.clonePath(path)
+	}
+	if err := root.addRoute(path, handle); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
@@ -0,0 +1,103 @@
+package httprouter
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTryHandleConflicts(t *testing.T) {
+	r := New()
+	for _, path := range []string{"/users/:id", "/src/*filepath", "/cmd/:tool/"} {
+		if err := r.TryGET(path, fakeHandle); err != nil {
+			t.Fatalf("TryGET(%q): %v", path, err)
+		}
+	}
+	cases := []struct {
+		method, path string
+		reason       ConflictReason
+		existing     string
+	}{
+		{"GET", "/users/:id", ConflictDuplicate, "/users/:id"},
+		{"GET", "/users/new", ConflictWildcard, "/users/:id"},
+		{"GET", "/src/*filepath", ConflictDuplicate, "/src/*filepath"},
+		{"GET", "/src/*filepath/x", ConflictCatchAllPosition, "/src/*filepath"},
+		{"GET", "/src/*other", ConflictWildcard, "/src/*filepath"},
+		{"GET", "/files/*p/x", ConflictCatchAllPosition, ""},
+		{"GET", "/a/:", ConflictUnnamedWildcard, ""},
+		{"GET", "/b/:x:y", ConflictWildcardSegment, ""},
+		{"GET", "nope", ConflictInvalid, ""},
+		{"BAD METHOD", "/x", ConflictInvalid, ""},
+	}
+	for _, c := range cases {
+		err := r.TryHandle(c.method, c.path, fakeHandle)
+		var ce *RouteConflictError
+		if !errors.As(err, &ce) {
+			t.Errorf("%s %s: got %v, want *RouteConflictError", c.method, c.path, err)
+			continue
+		}
+		if ce.Reason != c.reason || ce.Existing != c.existing {
+			t.Errorf("%s %s: got reason %s existing %q, want %s %q",
+				c.method, c.path, ce.Reason, ce.Existing, c.reason, c.existing)
+		}
+	}
+}
+
+func TestTryHandleLeavesTreeUnchanged(t *testing.T) {
+	r := New()
+	for _, path := range []string{"/", "/users/:id", "/users/:id/posts", "/src/*filepath", "/search/", "/support"} {
+		r.GET(path, fakeHandle)
+	}
+	before := dumpNodes(r.trees["GET"])
+	for _, path := range []string{"/users/:name", "/users/:id/posts", "/src/*filepath/x", "/se:arch", "/support"} {
+		if r.TryGET(path, fakeHandle) == nil {
+			t.Errorf("TryGET(%q) succeeded", path)
+		}
+	}
+	if after := dumpNodes(r.trees["GET"]); !reflect.DeepEqual(before, after) {
+		t.Fatalf("tree changed:\n%s\n---\n%s", strings.Join(before, "\n"), strings.Join(after, "\n"))
+	}
+}
+
+// dumpNodes
+// 返回词典树中每个节点的字段,用于比较两颗词典树
+func dumpNodes(n *node) []string {
+	out := []string{fmt.Sprintf("%q %s %q wild=%v prio=%d max=%d %q",
+		n.path, n.nType, n.indices, n.wildChild, n.priority, n.maxParams, n.fullPath)}
+	for _, c := range n.children {
+		for _, line := range dumpNodes(c) {
+			out = append(out, "  "+line)
+		}
+	}
+	return out
+}
+
+func TestTryHandleCopiesOnlyChangedPath(t *testing.T) {
+	routes := []string{
+		"/", "/cmd/:tool/:sub", "/cmd/:tool/", "/src/*filepath", "/search/", "/search/:query",
+		"/user_:name", "/user_:name/about", "/files/:dir/*filepath", "/doc/", "/doc/go_faq.html",
+		"/doc/go1.html", "/info/:user/public", "/info/:user/project/:project",
+	}
+	want := New()
+	got := New()
+	for _, route := range routes {
+		want.GET(route, fakeHandle)
+		old := got.trees["GET"]
+		var before []string
+		if old != nil {
+			before = dumpNodes(old)
+		}
+		if err := got.TryGET(route, fakeHandle); err != nil {
+			t.Fatalf("TryGET(%q): %v", route, err)
+		}
+		if old != nil && !reflect.DeepEqual(before, dumpNodes(old)) {
+			t.Fatalf("TryGET(%q) modified the previous tree", route)
+		}
+	}
+	if !reflect.DeepEqual(dumpNodes(want.trees["GET"]), dumpNodes(got.trees["GET"])) {
+		t.Errorf("tree built by TryGET differs from GET:\n%s\n---\n%s",
+			strings.Join(dumpNodes(got.trees["GET"]), "\n"), strings.Join(dumpNodes(want.trees["GET"]), "\n"))
+	}
+}
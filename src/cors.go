@@ -0,0 +1,191 @@
+package httprouter
+
+/*
+	CORS
+		allowOrigin
+		allowHeaders
+		setOrigin
+		decorate
+		preflight
+	isPreflight
+	Router
+		SetCORS
+		corsFor
+*/
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// CORS
+// 跨域资源共享(Cross-Origin Resource Sharing)的配置
+// 预检请求使用为该路径实际注册的方法作答,普通请求的响应会被添加相应的响应头
+type CORS struct {
+	// 允许的来源,例如"https://example.com"
+	// "*"允许所有来源,也可以使用path.Match的通配符,例如"https://*.example.com"
+	AllowedOrigins []string
+
+	// 自定义的来源校验函数,设置后AllowedOrigins中没有匹配的来源会再交给它判断
+	AllowOriginFunc func(origin string) bool
+
+	// 允许的请求方法,如果未设置,则允许为该路径注册的所有方法
+	// 设置后,只有同时注册过的方法才会被允许
+	AllowedMethods []string
+
+	// 预检请求中允许的请求头,"*"允许所有请求头
+	// 如果未设置,只允许无需预检的简单请求头
+	AllowedHeaders []string
+
+	// 允许浏览器中的脚本读取的响应头
+	ExposedHeaders []string
+
+	// 是否允许携带Cookie等凭证
+	// 设置后,即使AllowedOrigins为"*",也会返回请求的来源而不是"*"
+	AllowCredentials bool
+
+	// 预检请求的结果可以被缓存的秒数,为0时不设置该响应头
+	MaxAge int
+}
+
+// allowOrigin
+// 判断给定的来源是否被允许
+func (c *CORS) allowOrigin(origin string) bool {
+	for _, o := range c.AllowedOrigins {
+		if o == "*" || o == origin {
+			return true
+		}
+		if ok, _ := path.Match(o, origin); ok {
+			return true
+		}
+	}
+	return c.AllowOriginFunc != nil && c.AllowOriginFunc(origin)
+}
+
+// allowHeaders
+// 判断预检请求中列出的请求头是否全部被允许
+func (c *CORS) allowHeaders(requested string) bool {
+	for _, h := range strings.Split(requested, ",") {
+		h = strings.TrimSpace(h)
+		if h == "" {
+			continue
+		}
+		ok := false
+		for _, a := range c.AllowedHeaders {
+			if a == "*" || strings.EqualFold(a, h) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// setOrigin
+// 设置Access-Control-Allow-Origin以及Access-Control-Allow-Credentials响应头
+func (c *CORS) setOrigin(header http.Header, origin string) {
+	if len(c.AllowedOrigins) == 1 && c.AllowedOrigins[0] == "*" && !c.AllowCredentials {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+	if c.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// decorate
+// 为一个跨域的普通请求设置Access-Control-Allow-Origin等响应头
+// 来源不被允许时不设置任何CORS响应头,浏览器会拒绝脚本读取响应
+func (c *CORS) decorate(w http.ResponseWriter, req *http.Request) {
+	header := w.Header()
+	header.Add("Vary", "Origin")
+	origin := req.Header.Get("Origin")
+	if !c.allowOrigin(origin) {
+		return
+	}
+	c.setOrigin(header, origin)
+	if len(c.ExposedHeaders) > 0 {
+		header.Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ", "))
+	}
+}
+
+// preflight
+// 响应一个CORS预检请求,allow为该路径实际注册的方法列表
+// 请求的来源,方法或者请求头中有任何一项不被允许时,不设置CORS响应头
+func (c *CORS) preflight(w http.ResponseWriter, req *http.Request, allow string) {
+	header := w.Header()
+	header.Add("Vary", "Origin")
+	header.Add("Vary", "Access-Control-Request-Method")
+	header.Add("Vary", "Access-Control-Request-Headers")
+	var methods []string
+	for _, m := range strings.Split(allow, ",") {
+		if c.AllowedMethods == nil {
+			methods = append(methods, m)
+			continue
+		}
+		for _, a := range c.AllowedMethods {
+			if strings.EqualFold(a, m) {
+				methods = append(methods, m)
+				break
+			}
+		}
+	}
+	method := req.Header.Get("Access-Control-Request-Method")
+	allowed := false
+	for _, m := range methods {
+		if m == method {
+			allowed = true
+			break
+		}
+	}
+	origin := req.Header.Get("Origin")
+	requested := req.Header.Get("Access-Control-Request-Headers")
+	if !allowed || !c.allowOrigin(origin) || !c.allowHeaders(requested) {
+		return
+	}
+	c.setOrigin(header, origin)
+	header.Set("Access-Control-Allow-Methods", strings.Join(methods, ","))
+	if requested != "" {
+		// 只返回本次请求中列出的请求头
+		header.Set("Access-Control-Allow-Headers", requested)
+	}
+	if c.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(c.MaxAge))
+	}
+}
+
+// isPreflight
+// 判断一个请求是否是CORS预检请求
+func isPreflight(req *http.Request) bool {
+	return req.Method == "OPTIONS" &&
+		req.Header.Get("Origin") != "" &&
+		req.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// SetCORS
+// 为给定的路由覆盖Router.CORS配置,path必须与注册路由时使用的路径完全相同
+// 该配置对这个路径上所有方法的路由都生效,cors为nil时对该路由关闭CORS
+func (r *Router) SetCORS(path string, cors *CORS) {
+	if r.corsRoutes == nil {
+		r.corsRoutes = make(map[string]*CORS)
+	}
+	r.corsRoutes[path] = cors
+}
+
+// corsFor
+// 返回给定方法与路径的请求所使用的CORS配置,没有配置时返回nil
+func (r *Router) corsFor(method, path string) *CORS {
+	if r.corsRoutes != nil {
+		if route := r.routePath(method, path); route != "" {
+			if c, ok := r.corsRoutes[route]; ok {
+				return c
+			}
+		}
+	}
+	return r.CORS
+}
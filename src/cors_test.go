@@ -0,0 +1,60 @@
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// preflightRequest
+// 返回一个跨域的预检请求
+func preflightRequest(path, method string) *http.Request {
+	req := httptest.NewRequest("OPTIONS", path, nil)
+	req.Header.Set("Origin", "https://app.example")
+	req.Header.Set("Access-Control-Request-Method", method)
+	return req
+}
+
+func TestCORSPreflight(t *testing.T) {
+	r := New()
+	r.CORS = &CORS{AllowedOrigins: []string{"https://app.example"}}
+	anyHit := false
+	r.GET("/users/:id", fakeHandle)
+	r.Any("/rpc", func(http.ResponseWriter, *http.Request, Params) { anyHit = true })
+
+	for _, c := range []struct{ path, method string }{
+		{"/users/1", "GET"},
+		{"/rpc", "POST"},
+	} {
+		anyHit = false
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, preflightRequest(c.path, c.method))
+		if anyHit {
+			t.Errorf("%s: preflight was passed to the Any handler", c.path)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example" {
+			t.Errorf("%s: Access-Control-Allow-Origin = %q", c.path, got)
+		}
+		if w.Header().Get("Access-Control-Allow-Methods") == "" {
+			t.Errorf("%s: missing Access-Control-Allow-Methods", c.path)
+		}
+	}
+
+	// 没有配置CORS时,OPTIONS请求仍然交给通过Any注册的路由
+	r.SetCORS("/rpc", nil)
+	anyHit = false
+	r.ServeHTTP(httptest.NewRecorder(), preflightRequest("/rpc", "POST"))
+	if !anyHit {
+		t.Error("preflight without CORS config was not passed to the Any handler")
+	}
+
+	// 跨域的普通请求带有CORS响应头
+	anyHit = false
+	req := httptest.NewRequest("GET", "/users/1", nil)
+	req.Header.Set("Origin", "https://app.example")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example" {
+		t.Errorf("simple request: Access-Control-Allow-Origin = %q", got)
+	}
+}
@@ -0,0 +1,229 @@
+package httprouter
+
+/*
+	RouteInfo
+	Router
+		SetRouteInfo
+		DebugHandler
+		countHit
+	routeStats
+		hit
+		get
+		start
+	debugHandler
+		ServeHTTP
+		snapshot
+	debugSnapshot
+	debugTree
+	debugRoute
+	countNodes
+*/
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RouteInfo
+// 路由的名称与元数据,只用于DebugHandler的展示
+type RouteInfo struct {
+	Name string
+	Meta map[string]string
+}
+
+// SetRouteInfo
+// 为给定的路由设置名称与元数据,path必须与注册路由时使用的路径完全相同
+// method为空字符串表示通过Any注册的路由
+func (r *Router) SetRouteInfo(method, path string, info RouteInfo) {
+	if r.routeInfo == nil {
+		r.routeInfo = make(map[string]RouteInfo)
+	}
+	r.routeInfo[method+" "+path] = info
+}
+
+// DebugHandler
+// 返回一个展示路由表的http.Handler,默认返回HTML页面,
+// 请求带有?format=json或者Accept中明确列出了JSON时返回JSON
+// 展示的内容包括每个请求方法的路由,通过SetRouteInfo设置的名称与元数据,
+// 每颗词典树的节点数目,以及设置了CountRouteHits时每个路由被请求的次数,例如:
+// 		router.CountRouteHits = true
+// 		router.Handler("GET", "/debug/routes", router.DebugHandler())
+func (r *Router) DebugHandler() http.Handler {
+	return &debugHandler{router: r}
+}
+
+// countHit
+// 设置了CountRouteHits时,为实际处理请求的路由增加一次计数
+// route为空字符串表示没有匹配到路由
+func (r *Router) countHit(tree, route string) {
+	if r.CountRouteHits {
+		r.stats.hit(tree, route)
+	}
+}
+
+// routeStats
+// 每个路由的请求计数,零值即可使用
+type routeStats struct {
+	hits      sync.Map      //键为"METHOD /path",值为*atomic.Uint64
+	unmatched atomic.Uint64 //没有匹配到路由的请求
+	since     atomic.Int64  //第一次计数的时间,单位为纳秒,尚未计数时为0
+}
+
+// hit
+// 为给定的路由增加一次计数,route为空字符串表示没有匹配到路由
+func (s *routeStats) hit(tree, route string) {
+	if s.since.Load() == 0 {
+		s.since.CompareAndSwap(0, time.Now().UnixNano())
+	}
+	if route == "" {
+		s.unmatched.Add(1)
+		return
+	}
+	key := tree + " " + route
+	c, ok := s.hits.Load(key)
+	if !ok {
+		c, _ = s.hits.LoadOrStore(key, new(atomic.Uint64))
+	}
+	c.(*atomic.Uint64).Add(1)
+}
+
+// get
+// 返回给定路由的请求次数
+func (s *routeStats) get(tree, route string) uint64 {
+	if c, ok := s.hits.Load(tree + " " + route); ok {
+		return c.(*atomic.Uint64).Load()
+	}
+	return 0
+}
+
+// start
+// 返回第一次计数的时间,尚未计数时返回零值
+func (s *routeStats) start() time.Time {
+	if ns := s.since.Load(); ns != 0 {
+		return time.Unix(0, ns)
+	}
+	return time.Time{}
+}
+
+// debugHandler
+// DebugHandler返回的处理器
+type debugHandler struct {
+	router *Router
+}
+
+// ServeHTTP
+// 根据请求返回HTML或者JSON格式的路由表
+func (h *debugHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	snap := h.snapshot()
+	w.Header().Set("Cache-Control", "no-store")
+	if req.URL.Query().Get("format") == "json" || prefersJSON(req.Header.Get("Accept")) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(snap)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	debugTemplate.Execute(w, snap)
+}
+
+// snapshot
+// 收集当前的路由表与计数,请求方法按照字典序排列,通过Any注册的路由最后以"ANY"列出
+func (h *debugHandler) snapshot() *debugSnapshot {
+	r := h.router
+	snap := &debugSnapshot{
+		Counting:  r.CountRouteHits,
+		Since:     r.stats.start(),
+		Unmatched: r.stats.unmatched.Load(),
+	}
+	methods := make([]string, 0, len(r.trees)+1)
+	for method := range r.trees {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	if r.anyTree != nil {
+		methods = append(methods, "")
+	}
+	for _, method := range methods {
+		root := r.trees[method]
+		if method == "" {
+			root = r.anyTree
+		}
+		tree := debugTree{Method: methodName(method), Nodes: countNodes(root)}
+		root.eachRoute(func(n *node) {
+			info := r.routeInfo[method+" "+n.fullPath]
+			tree.Routes = append(tree.Routes, debugRoute{
+				Path: n.fullPath,
+				Name: info.Name,
+				Meta: info.Meta,
+				Hits: r.stats.get(method, n.fullPath),
+			})
+		})
+		sort.Slice(tree.Routes, func(i, j int) bool { return tree.Routes[i].Path < tree.Routes[j].Path })
+		snap.Trees = append(snap.Trees, tree)
+	}
+	return snap
+}
+
+// debugSnapshot
+// DebugHandler展示的内容
+type debugSnapshot struct {
+	Counting  bool        `json:"counting"`
+	Since     time.Time   `json:"since"`
+	Unmatched uint64      `json:"unmatched"`
+	Trees     []debugTree `json:"trees"`
+}
+
+// debugTree
+// 一个请求方法的词典树
+type debugTree struct {
+	Method string       `json:"method"`
+	Nodes  int          `json:"nodes"`
+	Routes []debugRoute `json:"routes"`
+}
+
+// debugRoute
+// 一个路由
+type debugRoute struct {
+	Path string            `json:"path"`
+	Name string            `json:"name,omitempty"`
+	Meta map[string]string `json:"meta,omitempty"`
+	Hits uint64            `json:"hits"`
+}
+
+// countNodes
+// 返回以该节点为根的词典树中节点的数目
+func countNodes(n *node) int {
+	count := 1
+	for _, child := range n.children {
+		count += countNodes(child)
+	}
+	return count
+}
+
+// debugTemplate
+// DebugHandler的HTML页面
+var debugTemplate = template.Must(template.New("routes").Parse(`<!doctype html>
+<meta charset="utf-8">
+<title>Routes</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: left; }
+td.path { font-family: monospace; }
+td.hits { text-align: right; }
+</style>
+<h1>Routes</h1>
+<p>{{if not .Counting}}Request counting is disabled; set CountRouteHits to enable it.{{else if .Since.IsZero}}No requests counted yet.{{else}}Counting since {{.Since.Format "2006-01-02 15:04:05 MST"}}; {{.Unmatched}} unmatched requests.{{end}} <a href="?format=json">JSON</a></p>
+{{range .Trees}}
+<h2>{{.Method}}</h2>
+<p>{{len .Routes}} routes, {{.Nodes}} nodes</p>
+<table>
+<tr><th>Path</th><th>Name</th><th>Metadata</th><th>Hits</th></tr>
+{{range .Routes}}<tr><td class="path">{{.Path}}</td><td>{{.Name}}</td><td>{{range $k, $v := .Meta}}{{$k}}={{$v}}<br>{{end}}</td><td class="hits">{{.Hits}}</td></tr>
+{{end}}</table>
+{{end}}`))
@@ -0,0 +1,164 @@
+package httprouter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// debugJSON
+// 请求DebugHandler的JSON格式的路由表
+func debugJSON(t *testing.T, r *Router) *debugSnapshot {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.DebugHandler().ServeHTTP(w, httptest.NewRequest("GET", "/debug?format=json", nil))
+	var snap debugSnapshot
+	if err := json.Unmarshal(w.Body.Bytes(), &snap); err != nil {
+		t.Fatalf("decoding snapshot: %v\n%s", err, w.Body.String())
+	}
+	return &snap
+}
+
+// hitsOf
+// 返回路由表中给定路由的请求次数
+func hitsOf(snap *debugSnapshot, method, path string) uint64 {
+	for _, tree := range snap.Trees {
+		if tree.Method != method {
+			continue
+		}
+		for _, route := range tree.Routes {
+			if route.Path == path {
+				return route.Hits
+			}
+		}
+	}
+	return 0
+}
+
+func TestCountRouteHits(t *testing.T) {
+	r := New()
+	r.CountRouteHits = true
+	r.HandleHEAD = true
+	r.HandleMethodOverride = true
+	r.GET("/users/:id", fakeHandle)
+	r.DELETE("/x", fakeHandle)
+	r.POST("/x", fakeHandle)
+	r.Any("/rpc/*method", fakeHandle)
+
+	if snap := debugJSON(t, r); !snap.Counting || !snap.Since.IsZero() {
+		t.Errorf("before any request: counting %v, since %v", snap.Counting, snap.Since)
+	}
+
+	for _, c := range []struct {
+		method, path, override string
+	}{
+		{"GET", "/users/1", ""},
+		{"GET", "/users/2", ""},
+		{"HEAD", "/users/3", ""},
+		{"POST", "/x", "DELETE"},
+		{"POST", "/x", ""},
+		{"PUT", "/rpc/call", ""},
+		{"GET", "/missing", ""},
+		{"GET", "/users/1/", ""},
+	} {
+		req := httptest.NewRequest(c.method, c.path, nil)
+		if c.override != "" {
+			req.Header.Set(methodOverrideHeader, c.override)
+		}
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	snap := debugJSON(t, r)
+	for _, c := range []struct {
+		method, path string
+		hits         uint64
+	}{
+		// 通过GET处理的HEAD请求计入GET的路由
+		{"GET", "/users/:id", 3},
+		// 覆盖后的方法计入实际处理请求的路由
+		{"DELETE", "/x", 1},
+		{"POST", "/x", 1},
+		{"ANY", "/rpc/*method", 1},
+	} {
+		if got := hitsOf(snap, c.method, c.path); got != c.hits {
+			t.Errorf("%s %s: hits %d, want %d", c.method, c.path, got, c.hits)
+		}
+	}
+	// 404以及重定向的请求都没有匹配到路由
+	if snap.Unmatched != 2 {
+		t.Errorf("unmatched %d, want 2", snap.Unmatched)
+	}
+	if snap.Since.IsZero() {
+		t.Error("since not set after counting")
+	}
+}
+
+func TestCountRouteHitsDisabled(t *testing.T) {
+	r := New()
+	r.GET("/a", fakeHandle)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/a", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/b", nil))
+	snap := debugJSON(t, r)
+	if snap.Counting || hitsOf(snap, "GET", "/a") != 0 || snap.Unmatched != 0 {
+		t.Errorf("counted without CountRouteHits: %+v", snap)
+	}
+}
+
+func TestCountRouteHitsConcurrent(t *testing.T) {
+	r := New()
+	r.CountRouteHits = true
+	r.GET("/a", fakeHandle)
+	h := r.DebugHandler()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/a", nil))
+				h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/debug?format=json", nil))
+			}
+		}()
+	}
+	wg.Wait()
+	if got := hitsOf(debugJSON(t, r), "GET", "/a"); got != 800 {
+		t.Errorf("hits %d, want 800", got)
+	}
+}
+
+func TestDebugHandlerFormat(t *testing.T) {
+	r := New()
+	r.GET("/a", fakeHandle)
+	h := r.DebugHandler()
+	for _, c := range []struct {
+		url, accept, contentType string
+	}{
+		{"/debug", "", "text/html; charset=utf-8"},
+		{"/debug", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", "text/html; charset=utf-8"},
+		{"/debug", "*/*", "text/html; charset=utf-8"},
+		{"/debug", "application/json", "application/json"},
+		{"/debug", "application/json;q=0", "text/html; charset=utf-8"},
+		{"/debug?format=json", "text/html", "application/json"},
+	} {
+		req := httptest.NewRequest("GET", c.url, nil)
+		if c.accept != "" {
+			req.Header.Set("Accept", c.accept)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s Accept %q: status %d", c.url, c.accept, w.Code)
+		}
+		if got := w.Header().Get("Content-Type"); got != c.contentType {
+			t.Errorf("%s Accept %q: Content-Type %q, want %q", c.url, c.accept, got, c.contentType)
+		}
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/debug", nil))
+	if !strings.Contains(w.Body.String(), "Request counting is disabled") {
+		t.Errorf("HTML page does not mention disabled counting:\n%s", w.Body.String())
+	}
+}
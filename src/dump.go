@@ -0,0 +1,161 @@
+package httprouter
+
+/*
+	Router
+		WriteTrees
+		WriteTreesDOT
+		dumpRoots
+	dumpRoot
+	dumpText
+	dumpDOT
+	dotEscape
+*/
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WriteTrees
+// 以缩进的文本格式输出词典树,每个节点一行,显示路径片段,节点类型,优先权,maxParams,
+// 子节点的索引以及注册的路由,子节点按照检索时的顺序排列,可以据此观察incrementChildPrio的排序结果
+// 没有指定methods时按照字典序输出所有请求方法的词典树,通过Any注册的路由最后以"ANY"输出
+// 		GET
+// 		"/" root prio=3 maxParams=1 indices="us"
+// 		  [0 'u'] "users/" static prio=2 maxParams=1 wild
+// 		    [wild] ":id" param prio=2 maxParams=1 handle=/users/:id
+func (r *Router) WriteTrees(w io.Writer, methods ...string) error {
+	bw := bufio.NewWriter(w)
+	for i, root := range r.dumpRoots(methods) {
+		if i > 0 {
+			bw.WriteByte('\n')
+		}
+		bw.WriteString(root.method + "\n")
+		dumpText(bw, root.n, "", "")
+	}
+	return bw.Flush()
+}
+
+// WriteTreesDOT
+// 以Graphviz DOT格式输出词典树,每个请求方法一个子图,边上标注子节点在indices中的位置与索引字节
+// 注册了路由的节点以双线框显示,参数节点为椭圆,全匹配节点为六边形
+// 		router.WriteTreesDOT(f)
+// 		dot -Tsvg routes.dot -o routes.svg
+func (r *Router) WriteTreesDOT(w io.Writer, methods ...string) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph httprouter {\n")
+	bw.WriteString("\tnode [shape=box, fontname=\"monospace\"];\n")
+	id := 0
+	for i, root := range r.dumpRoots(methods) {
+		fmt.Fprintf(bw, "\tsubgraph cluster_%d {\n\t\tlabel=\"%s\";\n", i, dotEscape(root.method))
+		dumpDOT(bw, root.n, &id)
+		bw.WriteString("\t}\n")
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+// dumpRoot
+// 一颗待输出的词典树
+type dumpRoot struct {
+	method string
+	n      *node
+}
+
+// dumpRoots
+// 返回需要输出的词典树,没有指定methods时返回所有词典树
+func (r *Router) dumpRoots(methods []string) []dumpRoot {
+	if len(methods) == 0 {
+		for method := range r.trees {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		if r.anyTree != nil {
+			methods = append(methods, "ANY")
+		}
+	}
+	var roots []dumpRoot
+	for _, method := range methods {
+		n := r.trees[method]
+		if method == "ANY" && n == nil {
+			n = r.anyTree
+		}
+		if n != nil {
+			roots = append(roots, dumpRoot{method, n})
+		}
+	}
+	return roots
+}
+
+// dumpText
+// 输出一个节点及其子节点,edge为该节点在父节点中的位置
+func dumpText(w *bufio.Writer, n *node, indent, edge string) {
+	w.WriteString(indent)
+	if edge != "" {
+		w.WriteString(edge + " ")
+	}
+	fmt.Fprintf(w, "%q %s prio=%d maxParams=%d", n.path, n.nType, n.priority, n.maxParams)
+	if n.indices != "" {
+		fmt.Fprintf(w, " indices=%q", n.indices)
+	}
+	if n.wildChild {
+		w.WriteString(" wild")
+	}
+	if n.handle != nil {
+		w.WriteString(" handle=" + n.fullPath)
+	}
+	w.WriteByte('\n')
+	for i, child := range n.children {
+		edge := "[wild]"
+		if !n.wildChild && i < len(n.indices) {
+			edge = fmt.Sprintf("[%d %q]", i, n.indices[i])
+		}
+		dumpText(w, child, indent+"  ", edge)
+	}
+}
+
+// dumpDOT
+// 输出一个节点及其子节点,返回该节点的编号
+func dumpDOT(w *bufio.Writer, n *node, id *int) int {
+	self := *id
+	*id++
+	label := []string{fmt.Sprintf("%q", n.path), fmt.Sprintf("%s prio=%d maxParams=%d", n.nType, n.priority, n.maxParams)}
+	if n.indices != "" {
+		label = append(label, fmt.Sprintf("indices=%q", n.indices))
+	}
+	if n.handle != nil {
+		label = append(label, "handle="+n.fullPath)
+	}
+	attrs := ""
+	switch n.nType {
+	case param:
+		attrs = ", shape=ellipse"
+	case catchAll:
+		attrs = ", shape=hexagon"
+	}
+	if n.handle != nil {
+		attrs += ", peripheries=2"
+	}
+	escaped := make([]string, len(label))
+	for i := range label {
+		escaped[i] = dotEscape(label[i])
+	}
+	fmt.Fprintf(w, "\t\tn%d [label=\"%s\"%s];\n", self, strings.Join(escaped, `\n`), attrs)
+	for i, child := range n.children {
+		edge := "wild"
+		if !n.wildChild && i < len(n.indices) {
+			edge = fmt.Sprintf("%d %q", i, n.indices[i])
+		}
+		c := dumpDOT(w, child, id)
+		fmt.Fprintf(w, "\t\tn%d -> n%d [label=\"%s\"];\n", self, c, dotEscape(edge))
+	}
+	return self
+}
+
+// dotEscape
+// 转义DOT字符串中的'\'与'"'
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
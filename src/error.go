@@ -0,0 +1,197 @@
+package httprouter
+
+/*
+	ErrNotFound
+	ErrMethodNotAllowed
+	ErrNotImplemented
+	ErrPanic
+	ErrRedirectRefused
+	ErrForbidden
+	ErrFileSystem
+	RouteError
+		Error
+		Unwrap
+		HTTPStatus
+	Router
+		renderError
+	ProblemJSON
+		acceptsJSON
+		prefersJSON
+		jsonQuality
+*/
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// 由路由自身产生的错误的种类,可以通过errors.Is与RouteError比较
+var (
+	ErrNotFound         = errors.New("not found")
+	ErrMethodNotAllowed = errors.New("method not allowed")
+	ErrNotImplemented   = errors.New("method not implemented")
+	ErrPanic            = errors.New("handler panicked")
+	// 存在一个添加或者去掉'/'后可以匹配的路径,但是路由没有重定向过去,
+	// 例如RedirectTrailingSlash为false,或者请求方法为CONNECT
+	ErrRedirectRefused = errors.New("redirect refused")
+	// 静态文件没有读取权限
+	ErrForbidden = errors.New("forbidden")
+	// 读取静态文件或者目录时出错
+	ErrFileSystem = errors.New("file system error")
+)
+
+// RouteError
+// 一个由路由自身产生的错误,交给Router.ErrorRenderer生成响应
+type RouteError struct {
+	Err       error       //错误的种类,为上面的ErrXxx之一
+	Status    int         //响应的HTTP状态码
+	Allowed   []string    //405时允许的请求方法
+	Location  string      //ErrRedirectRefused时可以匹配的路径
+	Recovered interface{} //ErrPanic时从宕机中恢复的值
+}
+
+// Error
+// 实现error接口
+func (e *RouteError) Error() string {
+	switch {
+	case e.Recovered != nil:
+		return fmt.Sprintf("%v: %v", e.Err, e.Recovered)
+	case e.Location != "":
+		return fmt.Sprintf("%v: try '%s'", e.Err, e.Location)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap
+// 返回错误的种类
+func (e *RouteError) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatus
+// 返回错误对应的HTTP状态码,使ErrorStatus可以识别RouteError
+func (e *RouteError) HTTPStatus() int {
+	return e.Status
+}
+
+// renderError
+// 把路由产生的错误交给ErrorRenderer,如果未设置则生成纯文本的响应
+func (r *Router) renderError(w http.ResponseWriter, req *http.Request, e *RouteError) {
+	if r.ErrorRenderer != nil {
+		r.ErrorRenderer(w, req, e)
+		return
+	}
+	if e.Err == ErrNotFound || e.Err == ErrRedirectRefused {
+		http.NotFound(w, req)
+		return
+	}
+	http.Error(w, http.StatusText(e.Status), e.Status)
+}
+
+// ProblemJSON
+// 一个ErrorRenderer,按照RFC 7807生成application/problem+json格式的响应:
+// 		router.ErrorRenderer = httprouter.ProblemJSON
+// 405响应中会列出允许的请求方法,宕机的值不会出现在响应中
+// 没有Accept请求头或者接受任意类型(例如curl发送的"*/*")时同样生成JSON,
+// 只有Accept请求头明确不接受JSON时,例如只接受text/html或者JSON的q=0,才生成纯文本的响应
+func ProblemJSON(w http.ResponseWriter, req *http.Request, e *RouteError) {
+	if !acceptsJSON(req.Header.Get("Accept")) {
+		http.Error(w, http.StatusText(e.Status), e.Status)
+		return
+	}
+	problem := struct {
+		Type     string   `json:"type"`
+		Title    string   `json:"title"`
+		Status   int      `json:"status"`
+		Detail   string   `json:"detail,omitempty"`
+		Instance string   `json:"instance,omitempty"`
+		Allowed  []string `json:"allowed,omitempty"`
+		Location string   `json:"location,omitempty"`
+	}{
+		Type:     "about:blank",
+		Title:    http.StatusText(e.Status),
+		Status:   e.Status,
+		Instance: req.URL.Path,
+		Allowed:  e.Allowed,
+		Location: e.Location,
+	}
+	switch e.Err {
+	case ErrMethodNotAllowed:
+		problem.Detail = "method " + req.Method + " is not allowed for this resource"
+	case ErrNotImplemented:
+		problem.Detail = "method " + req.Method + " is not implemented by this server"
+	case ErrRedirectRefused:
+		problem.Detail = "no resource at this path, but one exists at " + e.Location
+	case ErrPanic:
+		problem.Detail = "the server encountered an internal error"
+	case ErrForbidden:
+		problem.Detail = "access to this resource is forbidden"
+	case ErrFileSystem:
+		problem.Detail = "the server could not read this resource"
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(e.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// acceptsJSON
+// 判断Accept请求头是否接受application/problem+json
+// 使用与JSON最具体的匹配,依次为JSON类型(application/json,application/problem+json以及其他+json类型),
+// application/*,*/*;最具体的匹配的q为0时不接受,没有任何匹配时也不接受
+// 请求头为空时接受
+func acceptsJSON(accept string) bool {
+	if strings.TrimSpace(accept) == "" {
+		return true
+	}
+	_, q := jsonQuality(accept)
+	return q > 0
+}
+
+// prefersJSON
+// 判断Accept请求头是否明确列出了JSON类型,通配符不算在内
+// 浏览器的Accept通常带有*/*,用于默认返回HTML的处理器
+func prefersJSON(accept string) bool {
+	specificity, q := jsonQuality(accept)
+	return specificity == 2 && q > 0
+}
+
+// jsonQuality
+// 返回Accept请求头中与JSON最具体的匹配及其q值
+// 具体程度为2(JSON类型),1(application/*),0(*/*),没有任何匹配时为-1
+func jsonQuality(accept string) (best int, q float64) {
+	best = -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		specificity := -1
+		switch {
+		case mediaType == "application/json" || mediaType == "application/problem+json" ||
+			strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"):
+			specificity = 2
+		case mediaType == "application/*":
+			specificity = 1
+		case mediaType == "*/*":
+			specificity = 0
+		}
+		if specificity < best || specificity < 0 {
+			continue
+		}
+		weight := 1.0
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				weight = f
+			}
+		}
+		if specificity > best || weight > q {
+			best, q = specificity, weight
+		}
+	}
+	return best, q
+}
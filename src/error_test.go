@@ -0,0 +1,53 @@
+package httprouter
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProblemJSONAccept(t *testing.T) {
+	r := New()
+	r.ErrorRenderer = ProblemJSON
+	for _, c := range []struct {
+		accept string
+		json   bool
+	}{
+		{"", true},
+		{"*/*", true},
+		{"application/json", true},
+		{"application/problem+json", true},
+		{"application/vnd.api+json", true},
+		{"application/*", true},
+		{"text/html, */*;q=0.8", true},
+		{"application/json;q=0.5, text/plain", true},
+		{"text/html", false},
+		{"text/plain", false},
+		{"*/*;q=0", false},
+		{"application/json;q=0", false},
+		{"application/json;q=0, */*", false},
+		{"application/*;q=0, */*", false},
+	} {
+		req := httptest.NewRequest("GET", "/missing", nil)
+		if c.accept != "" {
+			req.Header.Set("Accept", c.accept)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		ct := w.Header().Get("Content-Type")
+		if got := ct == "application/problem+json"; got != c.json {
+			t.Errorf("Accept %q: Content-Type %q, want problem+json %v", c.accept, ct, c.json)
+			continue
+		}
+		if !c.json {
+			continue
+		}
+		var problem struct {
+			Status   int    `json:"status"`
+			Instance string `json:"instance"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil || problem.Status != 404 || problem.Instance != "/missing" {
+			t.Errorf("Accept %q: body %q (%v)", c.accept, w.Body.String(), err)
+		}
+	}
+}
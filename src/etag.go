@@ -0,0 +1,68 @@
+package httprouter
+
+/*
+	ContentETag
+	etagCache
+		get
+	etagEntry
+*/
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ContentETag
+// 使用文件内容的SHA-256哈希值作为强ETag,而不是只依赖修改时间
+// 哈希值按文件缓存,文件的修改时间或者大小改变时重新计算
+// 预压缩的文件有各自的ETag
+func ContentETag() FileOption {
+	return func(s *fileServer) {
+		s.etags = &etagCache{tags: make(map[string]etagEntry)}
+	}
+}
+
+// etagCache
+// 按文件缓存的ETag,可以被并发访问
+type etagCache struct {
+	mu   sync.Mutex
+	tags map[string]etagEntry
+}
+
+// etagEntry
+// 一个缓存的ETag,以及计算它时文件的修改时间与大小
+type etagEntry struct {
+	modTime time.Time
+	size    int64
+	tag     string
+}
+
+// get
+// 返回文件的ETag,必要时读取文件内容计算哈希值,计算后文件的读取位置会被重置到开头
+// 读取失败时返回空字符串
+func (c *etagCache) get(key string, d fs.FileInfo, f http.File) string {
+	c.mu.Lock()
+	e, ok := c.tags[key]
+	c.mu.Unlock()
+	if ok && e.modTime.Equal(d.ModTime()) && e.size == d.Size() {
+		return e.tag
+	}
+	h := sha256.New()
+	_, err := io.Copy(h, f)
+	if _, serr := f.Seek(0, io.SeekStart); err != nil || serr != nil {
+		return ""
+	}
+	e = etagEntry{
+		modTime: d.ModTime(),
+		size:    d.Size(),
+		tag:     `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`,
+	}
+	c.mu.Lock()
+	c.tags[key] = e
+	c.mu.Unlock()
+	return e.tag
+}
@@ -0,0 +1,81 @@
+package httprouter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// contentTag
+// 返回ContentETag为给定内容生成的ETag
+func contentTag(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+func TestContentETag(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	file := &fstest.MapFile{Data: []byte("hello"), ModTime: modTime}
+	fsys := fstest.MapFS{
+		"a.txt":    file,
+		"a.txt.gz": {Data: []byte("compressed"), ModTime: modTime},
+	}
+	r := New()
+	r.ServeFS("/*filepath", fsys, ContentETag(), Precompressed("gzip"))
+	get := func(accept, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/a.txt", nil)
+		if accept != "" {
+			req.Header.Set("Accept-Encoding", accept)
+		}
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, c := range []struct {
+		name   string
+		change func()
+		accept string
+		tag    string
+	}{
+		{name: "first request", tag: contentTag("hello")},
+		{name: "precompressed file", accept: "gzip", tag: contentTag("compressed")},
+		{
+			// 修改时间与大小都没有改变,使用缓存的ETag而不重新读取文件
+			name:   "cached",
+			change: func() { file.Data = []byte("jello") },
+			tag:    contentTag("hello"),
+		},
+		{
+			name:   "modtime changed",
+			change: func() { file.ModTime = modTime.Add(time.Second) },
+			tag:    contentTag("jello"),
+		},
+		{
+			name:   "size changed",
+			change: func() { file.Data = []byte("jello!") },
+			tag:    contentTag("jello!"),
+		},
+	} {
+		if c.change != nil {
+			c.change()
+		}
+		w := get(c.accept, "")
+		if got := w.Header().Get("ETag"); got != c.tag {
+			t.Errorf("%s: ETag %s, want %s", c.name, got, c.tag)
+		}
+		if w := get(c.accept, c.tag); w.Code != http.StatusNotModified {
+			t.Errorf("%s: If-None-Match with current ETag: status %d, want 304", c.name, w.Code)
+		}
+	}
+	if w := get("", contentTag("hello")); w.Code != http.StatusOK {
+		t.Errorf("stale ETag: status %d, want 200", w.Code)
+	}
+}
@@ -0,0 +1,235 @@
+package httprouter
+
+/*
+	Decision
+		String
+	TraceStep
+	Trace
+		String
+		step
+		tracer
+	Router
+		Explain
+		explainRedirect
+*/
+import (
+	"fmt"
+	"strings"
+)
+
+// 请求最终的处理方式
+const (
+	DecisionMatch                 Decision = iota //匹配到路由
+	DecisionTrailingSlashRedirect                 //重定向到添加或者去掉'/'的路径
+	DecisionFixedPathRedirect                     //重定向到修正过的路径
+	DecisionNotImplemented                        //501 Not Implemented
+	DecisionOptions                               //自动的OPTIONS响应
+	DecisionMethodNotAllowed                      //405 Method Not Allowed
+	DecisionNotFound                              //404 Not Found
+)
+
+// Decision
+// Explain得出的请求的处理方式
+type Decision uint8
+
+// String
+// 返回处理方式的简短描述
+func (d Decision) String() string {
+	switch d {
+	case DecisionMatch:
+		return "match"
+	case DecisionTrailingSlashRedirect:
+		return "trailing slash redirect"
+	case DecisionFixedPathRedirect:
+		return "fixed path redirect"
+	case DecisionNotImplemented:
+		return "501 not implemented"
+	case DecisionOptions:
+		return "automatic OPTIONS response"
+	case DecisionMethodNotAllowed:
+		return "405 method not allowed"
+	case DecisionNotFound:
+		return "404 not found"
+	}
+	return "unknown"
+}
+
+// TraceStep
+// 检索过程中的一步
+type TraceStep struct {
+	Tree      string //所在的词典树,为请求方法或者"ANY"
+	Node      string //节点的路径片段,检索重定向等不针对节点的步骤为空字符串
+	Type      string //节点的类型:static,root,param,catchAll
+	Remaining string //到达该节点时尚未匹配的路径
+	Note      string //可读的说明
+}
+
+// Trace
+// Explain返回的检索过程
+type Trace struct {
+	Method string
+	Path   string
+	Steps  []TraceStep
+
+	Decision Decision
+	Tree     string //匹配到路由的词典树,仅对DecisionMatch有效
+	Route    string //匹配到的路由在注册时使用的完整路径,仅对DecisionMatch有效
+	Params   Params //捕获的参数,仅对DecisionMatch有效
+	Location string //重定向的目标路径,仅对重定向有效
+	Allow    string //允许的请求方法,仅对DecisionOptions与DecisionMethodNotAllowed有效
+}
+
+// String
+// 返回多行文本格式的检索过程,适合写入日志
+func (t *Trace) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", t.Method, t.Path)
+	for i, s := range t.Steps {
+		fmt.Fprintf(&b, "  %2d. [%s]", i+1, s.Tree)
+		if s.Type != "" {
+			fmt.Fprintf(&b, " %s %q remaining %q:", s.Type, s.Node, s.Remaining)
+		}
+		fmt.Fprintf(&b, " %s\n", s.Note)
+	}
+	fmt.Fprintf(&b, "=> %s", t.Decision)
+	switch t.Decision {
+	case DecisionMatch:
+		fmt.Fprintf(&b, " %s %s", t.Tree, t.Route)
+		for _, p := range t.Params {
+			fmt.Fprintf(&b, " %s=%q", p.Key, p.Value)
+		}
+	case DecisionTrailingSlashRedirect, DecisionFixedPathRedirect:
+		fmt.Fprintf(&b, " to %s", t.Location)
+	case DecisionOptions, DecisionMethodNotAllowed:
+		fmt.Fprintf(&b, " (Allow: %s)", t.Allow)
+	}
+	b.WriteByte('\n')
+	return b.String()
+}
+
+// step
+// 添加一个不针对节点的步骤
+func (t *Trace) step(tree, format string, args ...interface{}) {
+	t.Steps = append(t.Steps, TraceStep{Tree: tree, Note: fmt.Sprintf(format, args...)})
+}
+
+// tracer
+// 返回把getNode经过的每一个节点记录到t中的回调
+func (t *Trace) tracer(tree string) tracer {
+	return func(n *node, remaining, format string, args ...interface{}) {
+		t.Steps = append(t.Steps, TraceStep{
+			Tree:      tree,
+			Node:      n.path,
+			Type:      n.nType.String(),
+			Remaining: remaining,
+			Note:      fmt.Sprintf(format, args...),
+		})
+	}
+}
+
+// Explain
+// 逐步说明ServeHTTP会如何处理给定的请求方法与路径:
+// 经过的节点,比较的索引,捕获的参数,以及最终的处理方式
+// 结果只取决于注册的路由与Router的选项,不会调用任何处理器,
+// 不考虑HandleMethodOverride,CORS预检请求等依赖请求头的处理,也不考虑NotFound等处理器的具体行为
+// 适合写入日志或者在调试接口中展示
+func (r *Router) Explain(method, path string) *Trace {
+	t := &Trace{Method: method, Path: path}
+	match := func(tree string, root *node) bool {
+		leaf, ps, _ := root.getNode(path, t.tracer(tree))
+		if leaf == nil {
+			return false
+		}
+		t.Decision, t.Tree, t.Route, t.Params = DecisionMatch, tree, leaf.fullPath, ps
+		return true
+	}
+	root := r.trees[method]
+	var tsr bool
+	if root != nil {
+		var leaf *node
+		if leaf, t.Params, tsr = root.getNode(path, t.tracer(method)); leaf != nil {
+			t.Decision, t.Tree, t.Route = DecisionMatch, method, leaf.fullPath
+			return t
+		}
+		t.Params = nil
+	} else {
+		t.step(method, "no routes registered for method %s", method)
+	}
+	if method == "HEAD" && r.HandleHEAD && r.trees["GET"] != nil {
+		t.step("GET", "HandleHEAD is set, falling back to the GET tree")
+		if match("GET", r.trees["GET"]) {
+			return t
+		}
+	}
+	if r.anyTree != nil {
+		t.step("ANY", "falling back to routes registered with Any")
+		if match("ANY", r.anyTree) {
+			return t
+		}
+	}
+	if root != nil && r.explainRedirect(t, method, root, tsr) {
+		return t
+	}
+	if root := r.trees["GET"]; root != nil && method == "HEAD" && r.HandleHEAD {
+		// 与redirectHEAD相同,HEAD的词典树无法重定向时使用GET的词典树
+		_, _, tsr := root.getValue(path)
+		if r.explainRedirect(t, "GET", root, tsr) {
+			return t
+		}
+	}
+	if r.HandleNotImplemented && !r.implements(method) {
+		t.step(method, "no route handles method %s and HandleNotImplemented is set", method)
+		t.Decision = DecisionNotImplemented
+		return t
+	}
+	if method == "OPTIONS" && r.HandleOPTIONS {
+		if t.Allow = r.allowed(path); t.Allow != "" {
+			t.step(method, "HandleOPTIONS is set and the path allows %s", t.Allow)
+			t.Decision = DecisionOptions
+			return t
+		}
+	} else if r.HandleMethodNotAllowed {
+		if t.Allow = r.allowed(path); t.Allow != "" {
+			t.step(method, "the path is registered for %s", t.Allow)
+			t.Decision = DecisionMethodNotAllowed
+			return t
+		}
+	}
+	t.step(method, "no route matches and no other method is registered for the path")
+	t.Decision = DecisionNotFound
+	return t
+}
+
+// explainRedirect
+// 与redirect相同的判断过程,需要重定向时设置t的处理方式以及目标路径并返回true
+func (r *Router) explainRedirect(t *Trace, tree string, root *node, tsr bool) bool {
+	path := t.Path
+	if t.Method == "CONNECT" || path == "/" {
+		return false
+	}
+	if tsr {
+		if !r.RedirectTrailingSlash {
+			t.step(tree, "a route exists with a trailing slash added or removed, but RedirectTrailingSlash is not set")
+		} else {
+			t.Decision = DecisionTrailingSlashRedirect
+			if len(path) > 1 && path[len(path)-1] == '/' {
+				t.Location = path[:len(path)-1]
+			} else {
+				t.Location = path + "/"
+			}
+			t.step(tree, "a route exists for %s", t.Location)
+			return true
+		}
+	}
+	if r.RedirectFixedPath {
+		clean := CleanPath(path)
+		fixed, found := root.findCaseInsensitivePath(clean, r.RedirectTrailingSlash)
+		if found {
+			t.Decision, t.Location = DecisionFixedPathRedirect, string(fixed)
+			t.step(tree, "case-insensitive lookup of cleaned path %q found %s", clean, t.Location)
+			return true
+		}
+		t.step(tree, "case-insensitive lookup of cleaned path %q found nothing", clean)
+	}
+	return false
+}
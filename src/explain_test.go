@@ -0,0 +1,76 @@
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestExplainAgreesWithLookup(t *testing.T) {
+	r := New()
+	// 每个处理器把注册时使用的路径写入响应,用来与Explain的结果比较
+	routeHandle := func(route string) Handle {
+		return func(w http.ResponseWriter, _ *http.Request, _ Params) {
+			w.Write([]byte(route))
+		}
+	}
+	for _, route := range []string{
+		"/",
+		"/cmd/:tool/:sub",
+		"/cmd/:tool/",
+		"/src/*filepath",
+		"/search/",
+		"/search/:query",
+		"/user_:name",
+		"/user_:name/about",
+		"/files/:dir/*filepath",
+		"/doc/",
+		"/doc/go_faq.html",
+		"/info/:user/public",
+		"/info/:user/project/:project",
+	} {
+		r.GET(route, routeHandle(route))
+	}
+	r.POST("/users/:id", routeHandle("/users/:id"))
+	r.Any("/rpc/*method", routeHandle("/rpc/*method"))
+
+	paths := []string{
+		"/", "/cmd/test/", "/cmd/test", "/cmd/test/3", "/cmd/test/3/",
+		"/src/", "/src/some/file.png", "/search/", "/search/gopher", "/search/gopher/",
+		"/user_gopher", "/user_gopher/about", "/user_", "/files/js/inc/framework.js",
+		"/doc", "/doc/", "/doc/go_faq.html", "/doc/go_faq.html/", "/doc/other",
+		"/info/gordon/public", "/info/gordon/project/go", "/info/gordon",
+		"/users/42", "/rpc/Echo", "/rpc", "/nope", "/cmd", "/sr",
+	}
+	for _, method := range []string{"GET", "HEAD", "POST", "DELETE"} {
+		for _, path := range paths {
+			handle, ps, tsr := r.Lookup(method, path)
+			trace := r.Explain(method, path)
+			if r.trees[method] != nil && len(trace.Steps) == 0 {
+				t.Errorf("%s %s: Explain recorded no steps", method, path)
+			}
+			if handle == nil {
+				if trace.Decision == DecisionMatch {
+					t.Errorf("%s %s: Explain matched %s, Lookup did not", method, path, trace.Route)
+				}
+				if tsr && path != "/" && trace.Decision != DecisionTrailingSlashRedirect {
+					t.Errorf("%s %s: Lookup suggests a trailing slash redirect, Explain decided %s", method, path, trace.Decision)
+				}
+				continue
+			}
+			if trace.Decision != DecisionMatch {
+				t.Errorf("%s %s: Lookup matched, Explain decided %s\n%s", method, path, trace.Decision, trace)
+				continue
+			}
+			w := httptest.NewRecorder()
+			handle(w, httptest.NewRequest(method, path, nil), ps)
+			if route := w.Body.String(); route != trace.Route {
+				t.Errorf("%s %s: Explain matched %s, Lookup matched %s", method, path, trace.Route, route)
+			}
+			if !reflect.DeepEqual(ps, trace.Params) {
+				t.Errorf("%s %s: Explain params %v, Lookup params %v", method, path, trace.Params, ps)
+			}
+		}
+	}
+}
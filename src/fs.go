@@ -0,0 +1,316 @@
+package httprouter
+
+/*
+	FileOption
+	FileRoot
+	DirectoryListing
+	BlockDotfiles
+	CacheControl
+	cacheRule
+	fileServer
+		Open
+		serve
+		serveContent
+		cacheControl
+		listDir
+		fail
+		notFound
+	Router
+		ServeFS
+		serveFiles
+	hasDotSegment
+	catchAllParam
+*/
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// FileOption
+// ServeFiles与ServeFS的可选项
+type FileOption func(*fileServer)
+
+// FileRoot
+// 使用文件系统中的一个子目录作为根目录,例如embed.FS中的"dist"
+func FileRoot(dir string) FileOption {
+	return func(s *fileServer) {
+		s.root = path.Clean("/" + dir)
+	}
+}
+
+// DirectoryListing
+// 目录中没有index.html时是否列出目录内容,默认不列出,而是调用NotFound处理器
+func DirectoryListing(enabled bool) FileOption {
+	return func(s *fileServer) {
+		s.listDirs = enabled
+	}
+}
+
+// BlockDotfiles
+// 拒绝任何路径段以'.'开头的请求,例如"/.env"与"/.git/config",这些请求交给NotFound处理器
+// 目录列表中也不会显示这些文件
+func BlockDotfiles() FileOption {
+	return func(s *fileServer) {
+		s.blockDotfiles = true
+	}
+}
+
+// CacheControl
+// 为文件名匹配pattern的文件设置Cache-Control响应头,pattern使用path.Match的语法
+// pattern中不含'/'时与文件名匹配,例如"*.js";否则与相对于根目录的完整路径匹配,例如"/fonts/*"
+// 可以设置多条规则,第一条匹配的规则生效,并覆盖SPA模式设置的默认值
+func CacheControl(pattern, value string) FileOption {
+	if _, err := path.Match(pattern, ""); err != nil {
+		panic("invalid cache-control pattern '" + pattern + "': " + err.Error())
+	}
+	return func(s *fileServer) {
+		s.cacheRules = append(s.cacheRules, cacheRule{pattern, value})
+	}
+}
+
+// cacheRule
+// 一条由CacheControl设置的规则
+type cacheRule struct {
+	pattern string
+	value   string
+}
+
+// fileServer
+// 为ServeFiles与ServeFS注册的路由提供文件
+type fileServer struct {
+	router *Router
+	fs     http.FileSystem
+	param  string //全匹配参数的名称
+	root   string //文件系统中作为根目录的子目录
+	spa    *spaConfig
+
+	// 按照优先级排列的预压缩文件的编码,为空时不查找预压缩文件
+	encodings []string
+
+	listDirs      bool
+	blockDotfiles bool
+	cacheRules    []cacheRule
+	etags         *etagCache     //为空时使用http.ServeContent默认的基于修改时间的缓存
+	manifest      *assetManifest //为空时不使用带有指纹的文件名
+}
+
+// Open
+// 实现http.FileSystem接口,打开相对于根目录的文件
+func (s *fileServer) Open(name string) (http.File, error) {
+	return s.fs.Open(path.Join(s.root, name))
+}
+
+// serve
+// 提供全匹配参数所指定的文件,目录则提供其中的index.html
+// 文件不存在时调用Router的NotFound处理器,而不是http.NotFound
+func (s *fileServer) serve(w http.ResponseWriter, req *http.Request, ps Params) {
+	name := path.Clean("/" + ps.ByName(s.param))
+	if s.blockDotfiles && hasDotSegment(name) {
+		s.router.handleNotFound(w, req)
+		return
+	}
+	// 带有指纹的文件名对应原始文件,内容不会改变,可以被永久缓存
+	immutable := false
+	if s.manifest != nil {
+		if plain, ok := s.manifest.plain[name]; ok {
+			name, immutable = plain, true
+		}
+	}
+	f, err := s.Open(name)
+	if err != nil {
+		s.fail(w, req, err)
+		return
+	}
+	defer f.Close()
+	d, err := f.Stat()
+	if err != nil {
+		s.fail(w, req, err)
+		return
+	}
+	if d.IsDir() {
+		// 目录的URL必须以'/'结尾,否则页面中的相对链接会出错
+		if !strings.HasSuffix(req.URL.Path, "/") {
+			u := *req.URL
+			u.Path += "/"
+			http.Redirect(w, req, u.String(), http.StatusMovedPermanently)
+			return
+		}
+		index, err := s.Open(path.Join(name, "index.html"))
+		if errors.Is(err, fs.ErrNotExist) && s.listDirs {
+			s.listDir(w, req, f)
+			return
+		}
+		if err != nil {
+			s.fail(w, req, err)
+			return
+		}
+		name = path.Join(name, "index.html")
+		defer index.Close()
+		if d, err = index.Stat(); err != nil || d.IsDir() {
+			s.notFound(w, req)
+			return
+		}
+		f = index
+	}
+	s.serveContent(w, req, name, d, f, immutable)
+}
+
+// serveContent
+// 设置缓存相关的响应头并提供文件内容,name为相对于根目录的路径
+// immutable为true时文件通过带有指纹的文件名请求,总是被永久缓存
+func (s *fileServer) serveContent(w http.ResponseWriter, req *http.Request, name string, d fs.FileInfo, f http.File, immutable bool) {
+	header := w.Header()
+	if s.spa != nil {
+		s.spa.setCacheControl(header, name)
+	}
+	if value := s.cacheControl(name); value != "" {
+		header.Set("Cache-Control", value)
+	}
+	if immutable {
+		header.Set("Cache-Control", immutableCacheControl)
+	}
+	baseName, content, key := d.Name(), f, name
+	if s.encodings != nil {
+		// 响应内容取决于Accept-Encoding,即使提供的是原始文件
+		header.Add("Vary", "Accept-Encoding")
+		if cf, cd, encoding := s.openPrecompressed(req, name); cf != nil {
+			defer cf.Close()
+			// 使用原始文件的Content-Type,而不是根据压缩后的内容推测
+			header.Set("Content-Type", contentType(d.Name(), f))
+			header.Set("Content-Encoding", encoding)
+			d, content, key = cd, cf, name+";"+encoding
+		}
+	}
+	if s.etags != nil {
+		if tag := s.etags.get(key, d, content); tag != "" {
+			header.Set("ETag", tag)
+		}
+	}
+	http.ServeContent(w, req, baseName, d.ModTime(), content)
+}
+
+// cacheControl
+// 返回第一条与给定文件匹配的Cache-Control规则的值,没有匹配时返回空字符串
+func (s *fileServer) cacheControl(name string) string {
+	for _, rule := range s.cacheRules {
+		target := path.Base(name)
+		if strings.Contains(rule.pattern, "/") {
+			target = name
+		}
+		if ok, _ := path.Match(rule.pattern, target); ok {
+			return rule.value
+		}
+	}
+	return ""
+}
+
+// listDir
+// 列出目录的内容,格式与http.FileServer相同
+func (s *fileServer) listDir(w http.ResponseWriter, req *http.Request, f http.File) {
+	entries, err := f.Readdir(-1)
+	if err != nil {
+		s.router.renderError(w, req, &RouteError{
+			Err:    ErrFileSystem,
+			Status: http.StatusInternalServerError,
+		})
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, "<!doctype html>\n<meta name=\"viewport\" content=\"width=device-width\">\n<pre>\n")
+	for _, d := range entries {
+		name := d.Name()
+		if s.blockDotfiles && strings.HasPrefix(name, ".") {
+			continue
+		}
+		if d.IsDir() {
+			name += "/"
+		}
+		u := url.URL{Path: name}
+		fmt.Fprintf(w, "<a href=\"%s\">%s</a>\n", u.String(), html.EscapeString(name))
+	}
+	fmt.Fprintf(w, "</pre>\n")
+}
+
+// fail
+// 处理打开文件时的错误,没有权限时返回403,其他情况都当做文件不存在
+func (s *fileServer) fail(w http.ResponseWriter, req *http.Request, err error) {
+	if errors.Is(err, fs.ErrPermission) {
+		s.router.renderError(w, req, &RouteError{
+			Err:    ErrForbidden,
+			Status: http.StatusForbidden,
+		})
+		return
+	}
+	s.notFound(w, req)
+}
+
+// notFound
+// 文件不存在时,在SPA模式下提供回退文件,否则调用Router的NotFound处理器
+func (s *fileServer) notFound(w http.ResponseWriter, req *http.Request) {
+	if s.spa != nil && s.spa.fallbackFor(req.URL.Path) {
+		if f, err := s.Open(s.spa.fallback); err == nil {
+			defer f.Close()
+			if d, err := f.Stat(); err == nil && !d.IsDir() {
+				s.serveContent(w, req, s.spa.fallback, d, f, false)
+				return
+			}
+		}
+	}
+	s.router.handleNotFound(w, req)
+}
+
+// ServeFS
+// 与ServeFiles相同,但是从一个io/fs文件系统中读取文件,例如embed.FS
+// 路径可以以任意名称的全匹配参数结尾,例如:
+// 		//go:embed assets
+// 		var assets embed.FS
+// 		router.ServeFS("/static/*path", assets, httprouter.FileRoot("assets"))
+func (r *Router) ServeFS(path string, fsys fs.FS, opts ...FileOption) {
+	r.serveFiles(path, http.FS(fsys), opts)
+}
+
+// serveFiles
+// ServeFiles与ServeFS共用的注册过程
+func (r *Router) serveFiles(path string, root http.FileSystem, opts []FileOption) {
+	s := &fileServer{
+		router: r,
+		fs:     root,
+		param:  catchAllParam(path),
+		root:   "/",
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.manifest != nil {
+		s.manifest.build(s, path[:len(path)-len(s.param)-1])
+	}
+	r.GET(path, s.serve)
+	r.HEAD(path, s.serve)
+	if s.manifest != nil {
+		r.assets = append(r.assets, s.manifest)
+	}
+}
+
+// hasDotSegment
+// 判断路径中是否有以'.'开头的路径段
+func hasDotSegment(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.Contains(name, "/.")
+}
+
+// catchAllParam
+// 返回路径末尾的全匹配参数的名称,路径不以全匹配参数结尾时触发宕机
+func catchAllParam(path string) string {
+	i := strings.LastIndex(path, "/*")
+	if i < 0 || len(path) == i+2 || strings.IndexByte(path[i+2:], '/') >= 0 {
+		panic("path must end with a named catch-all parameter in path '" + path + "'")
+	}
+	return path[i+2:]
+}
@@ -0,0 +1,185 @@
+package httprouter
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// faultyFS
+// 对"secret.txt"返回没有权限的错误,"broken"目录无法读取内容
+type faultyFS struct {
+	fstest.MapFS
+}
+
+// Open
+// 实现fs.FS接口
+func (f faultyFS) Open(name string) (fs.File, error) {
+	if name == "secret.txt" {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	file, err := f.MapFS.Open(name)
+	if err != nil || name != "broken" {
+		return file, err
+	}
+	// 隐藏ReadDir方法,http.FS在列出目录时会返回错误
+	return struct{ fs.File }{file}, nil
+}
+
+func TestServeFSErrorsUseErrorRenderer(t *testing.T) {
+	fsys := faultyFS{fstest.MapFS{
+		"broken/a.txt": {Data: []byte("a")},
+	}}
+	for _, c := range []struct {
+		path   string
+		status int
+		err    error
+	}{
+		{"/static/secret.txt", http.StatusForbidden, ErrForbidden},
+		{"/static/broken/", http.StatusInternalServerError, ErrFileSystem},
+	} {
+		var got *RouteError
+		r := New()
+		r.ErrorRenderer = func(w http.ResponseWriter, req *http.Request, e *RouteError) {
+			got = e
+			ProblemJSON(w, req, e)
+		}
+		r.ServeFS("/static/*filepath", fsys, DirectoryListing(true))
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", c.path, nil)
+		req.Header.Set("Accept", "application/json")
+		r.ServeHTTP(w, req)
+		if got == nil || !errors.Is(got, c.err) {
+			t.Errorf("%s: ErrorRenderer got %v, want %v", c.path, got, c.err)
+		}
+		if w.Code != c.status {
+			t.Errorf("%s: status %d, want %d", c.path, w.Code, c.status)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("%s: Content-Type %q, want application/problem+json", c.path, ct)
+		}
+
+		// 未设置ErrorRenderer时仍然生成纯文本的响应
+		r.ErrorRenderer = nil
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", c.path, nil))
+		if w.Code != c.status || !strings.Contains(w.Body.String(), http.StatusText(c.status)) {
+			t.Errorf("%s: plain response %d %q", c.path, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestServeFSCacheControl(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":  {Data: []byte("index")},
+		"js/app.js":   {Data: []byte("app")},
+		"css/app.css": {Data: []byte("css")},
+	}
+	for _, c := range []struct {
+		name string
+		opts []FileOption
+		path string
+		want string
+	}{
+		{
+			name: "file name rule first", path: "/js/app.js",
+			opts: []FileOption{CacheControl("*.js", "max-age=60"), CacheControl("/js/*", "max-age=3600")},
+			want: "max-age=60",
+		},
+		{
+			name: "path rule first", path: "/js/app.js",
+			opts: []FileOption{CacheControl("/js/*", "max-age=3600"), CacheControl("*.js", "max-age=60")},
+			want: "max-age=3600",
+		},
+		{
+			name: "later rule when earlier does not match", path: "/css/app.css",
+			opts: []FileOption{CacheControl("*.js", "max-age=60"), CacheControl("/css/*", "no-store")},
+			want: "no-store",
+		},
+		{
+			name: "no rule matches", path: "/css/app.css",
+			opts: []FileOption{CacheControl("*.js", "max-age=60")},
+		},
+		{
+			name: "pattern without '/' does not match directories", path: "/js/app.js",
+			opts: []FileOption{CacheControl("js", "max-age=60")},
+		},
+		{
+			name: "rule overrides the SPA default", path: "/missing",
+			opts: []FileOption{SPA("index.html"), CacheControl("index.html", "max-age=60")},
+			want: "max-age=60",
+		},
+	} {
+		r := New()
+		r.ServeFS("/*filepath", fsys, c.opts...)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", c.path, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status %d", c.name, w.Code)
+		}
+		if got := w.Header().Get("Cache-Control"); got != c.want {
+			t.Errorf("%s: Cache-Control %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestServeFSDirectoriesAndDotfiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		".env":             {Data: []byte("SECRET=1")},
+		".git/config":      {Data: []byte("[core]")},
+		"docs/.draft.md":   {Data: []byte("draft")},
+		"docs/guide.md":    {Data: []byte("guide")},
+		"docs/api/ref.md":  {Data: []byte("ref")},
+		"site/index.html":  {Data: []byte("site")},
+		"site/.hidden.css": {Data: []byte("hidden")},
+	}
+	for _, c := range []struct {
+		name     string
+		opts     []FileOption
+		path     string
+		status   int
+		contains []string
+		excludes []string
+	}{
+		{name: "listing disabled", path: "/docs/", status: 404},
+		{
+			name: "listing", opts: []FileOption{DirectoryListing(true)}, path: "/docs/", status: 200,
+			contains: []string{`href="guide.md"`, `href="api/"`, `href=".draft.md"`},
+		},
+		{
+			name: "listing hides dotfiles", opts: []FileOption{DirectoryListing(true), BlockDotfiles()}, path: "/docs/", status: 200,
+			contains: []string{`href="guide.md"`}, excludes: []string{".draft.md"},
+		},
+		{name: "index.html instead of listing", opts: []FileOption{DirectoryListing(true)}, path: "/site/", status: 200, contains: []string{"site"}},
+		{name: "dotfile served by default", path: "/.env", status: 200},
+		{name: "dotfile blocked", opts: []FileOption{BlockDotfiles()}, path: "/.env", status: 404},
+		{name: "dot directory blocked", opts: []FileOption{BlockDotfiles()}, path: "/.git/config", status: 404},
+		{name: "nested dotfile blocked", opts: []FileOption{BlockDotfiles()}, path: "/site/.hidden.css", status: 404},
+		{name: "regular file with BlockDotfiles", opts: []FileOption{BlockDotfiles()}, path: "/docs/guide.md", status: 200},
+	} {
+		r := New()
+		r.ServeFS("/*filepath", fsys, c.opts...)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", c.path, nil))
+		if w.Code != c.status {
+			t.Errorf("%s: GET %s: status %d, want %d", c.name, c.path, w.Code, c.status)
+			continue
+		}
+		body := w.Body.String()
+		for _, s := range c.contains {
+			if !strings.Contains(body, s) {
+				t.Errorf("%s: body missing %q:\n%s", c.name, s, body)
+			}
+		}
+		for _, s := range c.excludes {
+			if strings.Contains(body, s) {
+				t.Errorf("%s: body should not contain %q:\n%s", c.name, s, body)
+			}
+		}
+	}
+}
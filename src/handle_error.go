@@ -0,0 +1,129 @@
+package httprouter
+
+/*
+	ErrorHandle
+	HTTPError
+		Error
+		Unwrap
+		HTTPStatus
+	ErrorStatus
+	Router
+		HandleE
+		GETE
+		POSTE
+		PUTE
+		PATCHE
+		DELETEE
+		handleError
+*/
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrorHandle
+// 与Handle相同,但是可以返回一个错误,由Router.ErrorHandler统一处理
+type ErrorHandle func(http.ResponseWriter, *http.Request, Params) error
+
+// HTTPError
+// 一个带有HTTP状态码的错误,ErrorHandle可以返回它来决定错误响应的状态码
+type HTTPError struct {
+	Status int
+	Err    error
+}
+
+// Error
+// 实现error接口
+func (e *HTTPError) Error() string {
+	if e.Err == nil {
+		return http.StatusText(e.Status)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap
+// 返回被包装的错误
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatus
+// 返回错误对应的HTTP状态码
+func (e *HTTPError) HTTPStatus() int {
+	return e.Status
+}
+
+// ErrorStatus
+// 返回错误对应的HTTP状态码
+// 错误链中任何一个实现了HTTPStatus() int方法的错误决定状态码,否则为500
+func ErrorStatus(err error) int {
+	var s interface{ HTTPStatus() int }
+	if errors.As(err, &s) {
+		if status := s.HTTPStatus(); status >= 400 && status <= 599 {
+			return status
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// HandleE
+// 与Handle相同,但是注册一个ErrorHandle,其返回的非nil错误交给ErrorHandler处理
+func (r *Router) HandleE(method, path string, handle ErrorHandle) {
+	r.Handle(method, path, func(w http.ResponseWriter, req *http.Request, ps Params) {
+		rw := wrapWriter(w)
+		if err := handle(rw, req, ps); err != nil {
+			r.handleError(rw, req, err)
+		}
+	})
+}
+
+//GETE
+//快捷调用router.HandleE("GET", path, handle)
+func (r *Router) GETE(path string, handle ErrorHandle) {
+	r.HandleE("GET", path, handle)
+}
+
+//POSTE
+//快捷调用router.HandleE("POST", path, handle)
+func (r *Router) POSTE(path string, handle ErrorHandle) {
+	r.HandleE("POST", path, handle)
+}
+
+//PUTE
+//快捷调用router.HandleE("PUT", path, handle)
+func (r *Router) PUTE(path string, handle ErrorHandle) {
+	r.HandleE("PUT", path, handle)
+}
+
+//PATCHE
+//快捷调用router.HandleE("PATCH", path, handle)
+func (r *Router) PATCHE(path string, handle ErrorHandle) {
+	r.HandleE("PATCH", path, handle)
+}
+
+//DELETEE
+//快捷调用router.HandleE("DELETE", path, handle)
+func (r *Router) DELETEE(path string, handle ErrorHandle) {
+	r.HandleE("DELETE", path, handle)
+}
+
+// handleError
+// 把ErrorHandle返回的错误交给ErrorHandler
+// 如果未设置,并且响应尚未开始,写入一个纯文本的错误响应,
+// 4xx错误使用错误本身的信息,5xx错误只使用状态码对应的文本,以免泄露内部信息
+func (r *Router) handleError(w http.ResponseWriter, req *http.Request, err error) {
+	if r.ErrorHandler != nil {
+		r.ErrorHandler(w, req, err)
+		return
+	}
+	if HeaderWritten(w) {
+		// 响应已经开始,无法再写入错误
+		return
+	}
+	status := ErrorStatus(err)
+	msg := http.StatusText(status)
+	if status < 500 {
+		msg = err.Error()
+	}
+	http.Error(w, msg, status)
+}
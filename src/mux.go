@@ -0,0 +1,195 @@
+package httprouter
+
+/*
+	MuxRoute
+	ParseMuxPath
+		muxBraces
+		muxSpansSegments
+	Router
+		HandleMux
+*/
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// MuxRoute
+// 一条由gorilla/mux风格路径转换而来的路由
+type MuxRoute struct {
+	// 转换后的词典树路径,例如"/articles/:category/:id"
+	Path string
+
+	// 转换时有损的地方,每一条都是一段可读的说明
+	// 有损的路由仍然可以注册,但行为与gorilla/mux不完全一致
+	Lossy []string
+
+	// 带有内联正则表达式的参数,在处理请求时校验
+	patterns map[string]*regexp.Regexp
+}
+
+// ParseMuxPath
+// 把gorilla/mux风格的路径转换为词典树所使用的:param/*catchall语法
+// 支持的写法:
+// 		{name}            -> :name
+// 		{name:[0-9]+}     -> :name,并在处理请求时用正则表达式校验参数值
+// 		{name:.*}         -> *name,仅当它是路径最后一段时(.+同理)
+// 		/user_{name}      -> /user_:name,通配符前可以有字面前缀
+// 无法被词典树表示的写法会返回错误,例如一个路径段中有多个变量、变量后还有字面后缀,
+// 或者字面路径中包含':'与'*'
+func ParseMuxPath(path string) (*MuxRoute, error) {
+	fail := func(format string, args ...interface{}) (*MuxRoute, error) {
+		return nil, fmt.Errorf("unsupported mux path '%s': %s", path, fmt.Sprintf(format, args...))
+	}
+	if path == "" || path[0] != '/' {
+		return fail("path must begin with '/'")
+	}
+	idxs, err := muxBraces(path)
+	if err != nil {
+		return fail("%v", err)
+	}
+	route := &MuxRoute{}
+	seen := make(map[string]bool)
+	var b strings.Builder
+	end := 0
+	for i := 0; i < len(idxs); i += 2 {
+		start := idxs[i]
+		literal := path[end:start]
+		if strings.ContainsAny(literal, ":*") {
+			return fail("literal ':' or '*' in '%s' cannot be represented", literal)
+		}
+		b.WriteString(literal)
+		end = idxs[i+1]
+		name, expr, hasExpr := strings.Cut(path[start+1:end-1], ":")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return fail("missing name in variable '%s'", path[start:end])
+		}
+		if strings.ContainsAny(name, "/:*") {
+			return fail("bad variable name '%s'", name)
+		}
+		if seen[name] {
+			return fail("duplicate variable name '%s'", name)
+		}
+		seen[name] = true
+		// 变量必须一直延伸到路径段结束,因此一个路径段中只能有一个变量,且变量后不能有字面后缀
+		if end < len(path) && path[end] != '/' {
+			return fail("variable '%s' must extend to the end of its path segment", name)
+		}
+		last := end == len(path)
+		if hasExpr && (expr == ".*" || expr == ".+") && last && path[start-1] == '/' {
+			// 匹配剩余全部路径的变量转换为全匹配参数
+			b.WriteString("*" + name)
+		} else {
+			b.WriteString(":" + name)
+			if hasExpr && muxSpansSegments(expr) {
+				route.Lossy = append(route.Lossy, fmt.Sprintf(
+					"variable '%s' pattern '%s' may match '/' but is limited to one path segment", name, expr))
+			}
+		}
+		if !hasExpr {
+			continue
+		}
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return fail("bad pattern for variable '%s': %v", name, err)
+		}
+		if route.patterns == nil {
+			route.patterns = make(map[string]*regexp.Regexp)
+		}
+		route.patterns[name] = re
+		if expr != ".*" {
+			// 词典树不能按照正则表达式回溯,不匹配的请求会直接得到404
+			route.Lossy = append(route.Lossy, fmt.Sprintf(
+				"variable '%s' pattern '%s' is checked after matching; non-matching requests get 404 instead of trying other routes", name, expr))
+		}
+	}
+	literal := path[end:]
+	if strings.ContainsAny(literal, ":*") {
+		return fail("literal ':' or '*' in '%s' cannot be represented", literal)
+	}
+	b.WriteString(literal)
+	route.Path = b.String()
+	return route, nil
+}
+
+// muxBraces
+// 返回路径中每个最外层'{'与'}'的位置,结束位置为'}'之后的索引
+// 正则表达式中可以包含成对的花括号,例如{id:[0-9]{3}}
+func muxBraces(s string) ([]int, error) {
+	var level, idx int
+	var idxs []int
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			if level++; level == 1 {
+				idx = i
+			}
+		case '}':
+			if level--; level == 0 {
+				idxs = append(idxs, idx, i+1)
+			} else if level < 0 {
+				return nil, fmt.Errorf("unbalanced braces")
+			}
+		}
+	}
+	if level != 0 {
+		return nil, fmt.Errorf("unbalanced braces")
+	}
+	return idxs, nil
+}
+
+// muxSpansSegments
+// 粗略判断一个正则表达式是否可能匹配到'/'
+func muxSpansSegments(expr string) bool {
+	// 转义的'.'与排除了'/'的字符集不会匹配到'/'
+	expr = strings.NewReplacer(`\.`, "", "[^/", "").Replace(expr)
+	for _, s := range []string{"/", ".", `\S`, `\W`, `\D`, "[^"} {
+		if strings.Contains(expr, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleMux
+// 以gorilla/mux风格的路径注册一个新的请求处理器,路径的转换规则见ParseMuxPath
+// 带有正则表达式的变量在请求匹配到路由之后校验,不满足时调用NotFound处理器
+// 与*catchall一样,全匹配变量的值以'/'开头,校验时会去掉开头的'/'
+// 返回转换中有损的地方;无法转换时返回错误,与已有路由冲突时返回*RouteConflictError,
+// 这两种情况下都不会注册任何路由
+func (r *Router) HandleMux(method, path string, handle Handle) ([]string, error) {
+	route, err := ParseMuxPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(route.patterns) == 0 {
+		if err := r.TryHandle(method, route.Path, handle); err != nil {
+			return nil, err
+		}
+		return route.Lossy, nil
+	}
+	patterns := route.patterns
+	err = r.TryHandle(method, route.Path, func(w http.ResponseWriter, req *http.Request, ps Params) {
+		for i := range ps {
+			re := patterns[ps[i].Key]
+			if re == nil {
+				continue
+			}
+			value := ps[i].Value
+			if strings.HasSuffix(route.Path, "*"+ps[i].Key) {
+				value = strings.TrimPrefix(value, "/")
+			}
+			if !re.MatchString(value) {
+				r.handleNotFound(w, req)
+				return
+			}
+		}
+		handle(w, req, ps)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return route.Lossy, nil
+}
@@ -0,0 +1,62 @@
+package httprouter
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseMuxPath(t *testing.T) {
+	for _, c := range []struct{ in, want string }{
+		{"/articles/{category}/{id:[0-9]+}", "/articles/:category/:id"},
+		{"/static/{path:.*}", "/static/*path"},
+		{"/user_{name}", "/user_:name"},
+		{"/codes/{code:[0-9]{3}}", "/codes/:code"},
+	} {
+		route, err := ParseMuxPath(c.in)
+		if err != nil {
+			t.Errorf("ParseMuxPath(%q): %v", c.in, err)
+			continue
+		}
+		if route.Path != c.want {
+			t.Errorf("ParseMuxPath(%q) = %q, want %q", c.in, route.Path, c.want)
+		}
+	}
+	for _, in := range []string{"articles", "/{a}{b}", "/{a}.json", "/{a", "/{}", "/{a}/{a}", "/a:b"} {
+		if _, err := ParseMuxPath(in); err == nil {
+			t.Errorf("ParseMuxPath(%q) succeeded", in)
+		}
+	}
+}
+
+func TestHandleMux(t *testing.T) {
+	r := New()
+	hit := false
+	if _, err := r.HandleMux("GET", "/items/{id:[0-9]+}", func(http.ResponseWriter, *http.Request, Params) { hit = true }); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		path string
+		hit  bool
+		code int
+	}{
+		{"/items/42", true, http.StatusOK},
+		{"/items/abc", false, http.StatusNotFound},
+	} {
+		hit = false
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", c.path, nil))
+		if hit != c.hit || w.Code != c.code {
+			t.Errorf("GET %s: hit=%v code=%d, want hit=%v code=%d", c.path, hit, w.Code, c.hit, c.code)
+		}
+	}
+	// 与已有路由冲突时返回错误而不是触发宕机
+	for _, path := range []string{"/items/new", "/items/{name}"} {
+		_, err := r.HandleMux("GET", path, fakeHandle)
+		var ce *RouteConflictError
+		if !errors.As(err, &ce) {
+			t.Errorf("HandleMux(%q): got %v, want *RouteConflictError", path, err)
+		}
+	}
+}
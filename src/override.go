@@ -0,0 +1,75 @@
+package httprouter
+
+/*
+	methodOverrideHeader
+	methodOverrideField
+	defaultOverrideMethods
+	Router
+		lookupOverride
+		overrideMethod
+*/
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// 指定覆盖方法的请求头以及表单字段
+const (
+	methodOverrideHeader = "X-HTTP-Method-Override"
+	methodOverrideField  = "_method"
+)
+
+// defaultOverrideMethods
+// 没有设置MethodOverrideMethods时允许覆盖的请求方法
+var defaultOverrideMethods = []string{"PUT", "PATCH", "DELETE"}
+
+// lookupOverride
+// 检索POST请求所指定的覆盖方法对应的路由
+// 如果找到了,把req.Method修改为该方法,使处理器看到的是覆盖后的方法,并返回路由所在的节点
+func (r *Router) lookupOverride(req *http.Request) (*node, Params) {
+	method := r.overrideMethod(req)
+	if method == "" {
+		return nil, nil
+	}
+	root := r.trees[method]
+	if root == nil {
+		return nil, nil
+	}
+	leaf, ps, _ := root.getNode(req.URL.Path, nil)
+	if leaf == nil {
+		return nil, nil
+	}
+	req.Method = method
+	return leaf, ps
+}
+
+// overrideMethod
+// 从请求头或者表单字段中读取覆盖方法,并用允许列表进行校验
+// 只有application/x-www-form-urlencoded的请求体才会被解析,解析后的值仍然保存在req.PostForm中
+// 没有指定或者不被允许时返回空字符串
+func (r *Router) overrideMethod(req *http.Request) string {
+	method := req.Header.Get(methodOverrideHeader)
+	if method == "" {
+		ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if ct != "application/x-www-form-urlencoded" || req.ParseForm() != nil {
+			return ""
+		}
+		method = req.PostForm.Get(methodOverrideField)
+	}
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == "" {
+		return ""
+	}
+	allowed := r.MethodOverrideMethods
+	if allowed == nil {
+		allowed = defaultOverrideMethods
+	}
+	for _, m := range allowed {
+		// 覆盖方法会被转换为大写,允许列表同样不区分大小写
+		if strings.ToUpper(m) == method {
+			return method
+		}
+	}
+	return ""
+}
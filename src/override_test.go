@@ -0,0 +1,73 @@
+package httprouter
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMethodOverride(t *testing.T) {
+	const form = "application/x-www-form-urlencoded"
+	for _, c := range []struct {
+		name        string
+		allowed     []string
+		header      string
+		contentType string
+		body        string
+		route       string //匹配到的路由的方法
+		method      string //处理器看到的req.Method
+	}{
+		{name: "header", header: "DELETE", route: "DELETE", method: "DELETE"},
+		{name: "lowercase header", header: " delete ", route: "DELETE", method: "DELETE"},
+		{name: "form field", contentType: form, body: "_method=put&name=x", route: "PUT", method: "PUT"},
+		{name: "header wins over form", header: "DELETE", contentType: form, body: "_method=PUT", route: "DELETE", method: "DELETE"},
+		{name: "not in default allowlist", header: "GET", route: "POST", method: "POST"},
+		{name: "rejected by allowlist", allowed: []string{"DELETE"}, header: "PUT", route: "POST", method: "POST"},
+		{name: "lowercase allowlist", allowed: []string{"delete"}, header: "DELETE", route: "DELETE", method: "DELETE"},
+		{name: "no route for method", header: "PATCH", route: "POST", method: "POST"},
+		{name: "non-form body", contentType: "application/json", body: "_method=DELETE", route: "POST", method: "POST"},
+	} {
+		r := New()
+		r.HandleMethodOverride = true
+		r.MethodOverrideMethods = c.allowed
+		var route, method, body string
+		for _, m := range []string{"POST", "PUT", "DELETE", "GET"} {
+			m := m
+			r.Handle(m, "/items/:id", func(_ http.ResponseWriter, req *http.Request, _ Params) {
+				route, method = m, req.Method
+				b, _ := io.ReadAll(req.Body)
+				body = string(b)
+			})
+		}
+		req := httptest.NewRequest("POST", "/items/1", strings.NewReader(c.body))
+		if c.header != "" {
+			req.Header.Set(methodOverrideHeader, c.header)
+		}
+		if c.contentType != "" {
+			req.Header.Set("Content-Type", c.contentType)
+		}
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		if route != c.route || method != c.method {
+			t.Errorf("%s: served by %s route with method %s, want %s route with method %s",
+				c.name, route, method, c.route, c.method)
+		}
+		// 非表单的请求体不会被读取,处理器仍然可以读到完整的内容
+		if c.contentType != form && body != c.body {
+			t.Errorf("%s: handler read body %q, want %q", c.name, body, c.body)
+		}
+	}
+
+	// 未开启时不检查覆盖方法
+	r := New()
+	served := ""
+	r.POST("/items/:id", func(http.ResponseWriter, *http.Request, Params) { served = "POST" })
+	r.DELETE("/items/:id", func(http.ResponseWriter, *http.Request, Params) { served = "DELETE" })
+	req := httptest.NewRequest("POST", "/items/1", nil)
+	req.Header.Set(methodOverrideHeader, "DELETE")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if served != "POST" {
+		t.Errorf("override applied with HandleMethodOverride unset: served by %s", served)
+	}
+}
@@ -0,0 +1,78 @@
+package httprouter
+
+/*
+	panicKey
+	PanicInfo
+	PanicFromContext
+	LogPanics
+	logPanic
+*/
+import (
+	"context"
+	"log/slog"
+	"net/http"
+)
+
+// 交给PanicHandler的请求中,宕机的信息以PanicKey为键存储在context中
+var PanicKey = panicKey{}
+
+// panicKey
+// 以panicKey作为键存储PanicInfo
+type panicKey struct{}
+
+// PanicInfo
+// 一次从处理器中恢复的宕机
+type PanicInfo struct {
+	Value interface{} //从宕机中恢复的值
+	Stack []byte      //宕机时的调用堆栈
+	Route string      //匹配到的路由在注册时使用的路径,没有匹配的路由时为空字符串
+}
+
+// PanicFromContext
+// 从请求的context中提取宕机的信息,如果当前没有则返回nil
+// 仅在PanicHandler中可用
+func PanicFromContext(ctx context.Context) *PanicInfo {
+	info, _ := ctx.Value(PanicKey).(*PanicInfo)
+	return info
+}
+
+// LogPanics
+// 返回一个PanicHandler,使用log/slog记录宕机的值,匹配到的路由以及堆栈
+// 如果响应尚未开始,返回一个500响应;logger为nil时使用slog.Default()
+// 		router.PanicHandler = httprouter.LogPanics(nil)
+func LogPanics(logger *slog.Logger) func(http.ResponseWriter, *http.Request, interface{}) {
+	return func(w http.ResponseWriter, req *http.Request, rcv interface{}) {
+		l := logger
+		if l == nil {
+			l = slog.Default()
+		}
+		info := PanicFromContext(req.Context())
+		if info == nil {
+			info = &PanicInfo{Value: rcv}
+		}
+		logPanic(l, req, info)
+		if !HeaderWritten(w) {
+			http.Error(w,
+				http.StatusText(http.StatusInternalServerError),
+				http.StatusInternalServerError,
+			)
+		}
+	}
+}
+
+// logPanic
+// 记录请求的方法,路径,宕机的值,匹配到的路由以及堆栈
+func logPanic(l *slog.Logger, req *http.Request, info *PanicInfo) {
+	attrs := []any{
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+		slog.Any("panic", info.Value),
+	}
+	if info.Stack != nil {
+		attrs = append(attrs,
+			slog.String("route", info.Route),
+			slog.String("stack", string(info.Stack)),
+		)
+	}
+	l.ErrorContext(req.Context(), "httprouter: panic serving request", attrs...)
+}
@@ -0,0 +1,77 @@
+package httprouter
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// panicRouter
+// 返回一个在/boom/:id宕机,在/late写入响应后宕机的Router
+func panicRouter() *Router {
+	r := New()
+	r.GET("/boom/:id", func(http.ResponseWriter, *http.Request, Params) { panic("boom") })
+	r.GET("/late", func(w http.ResponseWriter, _ *http.Request, _ Params) {
+		w.Write([]byte("partial"))
+		panic("late")
+	})
+	r.GET("/abort", func(http.ResponseWriter, *http.Request, Params) { panic(http.ErrAbortHandler) })
+	return r
+}
+
+func TestLogPanics(t *testing.T) {
+	var buf bytes.Buffer
+	r := panicRouter()
+	r.PanicHandler = LogPanics(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/boom/1", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status %d, want 500", w.Code)
+	}
+	for _, want := range []string{"panic=boom", "route=/boom/:id", "stack="} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log %q does not contain %q", buf.String(), want)
+		}
+	}
+
+	// 响应已经开始时不再写入500
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/late", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "partial" {
+		t.Errorf("started response: status %d body %q", w.Code, w.Body.String())
+	}
+}
+
+func TestPanicAbortHandler(t *testing.T) {
+	r := panicRouter()
+	r.PanicHandler = LogPanics(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+	defer func() {
+		if rcv := recover(); rcv != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", rcv)
+		}
+	}()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/abort", nil))
+}
+
+func TestErrorRendererLogsPanics(t *testing.T) {
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	r := panicRouter()
+	r.ErrorRenderer = ProblemJSON
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/boom/1", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status %d, want 500", w.Code)
+	}
+	for _, want := range []string{"panic=boom", "route=/boom/:id", "stack="} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log %q does not contain %q", buf.String(), want)
+		}
+	}
+}
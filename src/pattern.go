@@ -0,0 +1,170 @@
+package httprouter
+
+/*
+	Router
+		HandlePattern
+	parsePattern
+		isIdentifier
+*/
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// HandlePattern
+// 以Go1.22 net/http.ServeMux的模式语法注册一个http.Handler,便于在两者之间迁移
+// 模式的格式为"[METHOD ][HOST]/[PATH]",会被转换为词典树所使用的:param/*catchall语法:
+// 		"GET /items/{id}"       ->  GET /items/:id
+// 		"/files/{path...}"      ->  Any /files/*path
+// 		"POST /items/{$}"       ->  POST /items/
+// 		"/static/"              ->  Any /static/*_
+// 与ServeMux的区别:
+// 		1.没有指定方法时通过Any注册,因此为某个方法注册的路由总是优先匹配
+// 		2."GET"不会同时注册"HEAD"
+// 		3.不支持指定主机,也不支持包含':'或'*'的字面路径段
+// 		4.ServeMux允许的某些模式组合无法被词典树表示,例如"GET /items/{id}"与"GET /items/new"
+// 处理器可以通过req.PathValue或者ParamsFromContext获取参数,
+// 与ServeMux一致,{name...}的值不包含开头的'/'
+// 模式无法被词典树表示时返回一个错误,此时不会注册任何路由;
+// 与已有路由冲突时返回的错误为*RouteConflictError
+func (r *Router) HandlePattern(pattern string, handler http.Handler) error {
+	method, path, err := parsePattern(pattern)
+	if err != nil {
+		return err
+	}
+	// 全匹配参数的名称,其值需要去掉开头的'/'
+	var catchAllName string
+	if i := strings.LastIndexByte(path, '*'); i >= 0 {
+		catchAllName = path[i+1:]
+	}
+	handle := func(w http.ResponseWriter, req *http.Request, ps Params) {
+		for i := range ps {
+			switch ps[i].Key {
+			case "_":
+				// 匿名的前缀匹配,ServeMux中没有对应的参数
+			case catchAllName:
+				req.SetPathValue(ps[i].Key, strings.TrimPrefix(ps[i].Value, "/"))
+			default:
+				req.SetPathValue(ps[i].Key, ps[i].Value)
+			}
+		}
+		ctx := context.WithValue(req.Context(), ParamsKey, ps)
+		handler.ServeHTTP(w, req.WithContext(ctx))
+	}
+	if method == "" {
+		return r.TryAny(path, handle)
+	}
+	return r.TryHandle(method, path, handle)
+}
+
+// parsePattern
+// 解析一个ServeMux模式,返回方法以及转换后的词典树路径
+// 方法为空表示匹配所有方法
+func parsePattern(pattern string) (method, path string, err error) {
+	fail := func(format string, args ...interface{}) (string, string, error) {
+		return "", "", fmt.Errorf("invalid pattern '%s': %s", pattern, fmt.Sprintf(format, args...))
+	}
+	if pattern == "" {
+		return fail("empty pattern")
+	}
+	rest := pattern
+	// 方法与路径之间以空格或者制表符分隔
+	if i := strings.IndexAny(rest, " \t"); i >= 0 {
+		method, rest = rest[:i], strings.TrimLeft(rest[i+1:], " \t")
+		if !isToken(method) {
+			return fail("bad method '%s'", method)
+		}
+	}
+	i := strings.IndexByte(rest, '/')
+	if i < 0 {
+		return fail("host/path missing /")
+	}
+	if i > 0 {
+		// 词典树只按照路径匹配,无法表示主机
+		return fail("host '%s' is not supported", rest[:i])
+	}
+	rest = rest[1:]
+
+	var b strings.Builder
+	seen := make(map[string]bool)
+	for {
+		b.WriteByte('/')
+		seg := rest
+		last := true
+		if j := strings.IndexByte(rest, '/'); j >= 0 {
+			seg, rest, last = rest[:j], rest[j+1:], false
+		}
+		if seg == "" && last {
+			// 以'/'结尾的模式匹配该前缀下的所有路径
+			if seen["_"] {
+				return fail("duplicate wildcard name '_'")
+			}
+			b.WriteString("*_")
+			break
+		}
+		if !strings.HasPrefix(seg, "{") {
+			if strings.ContainsAny(seg, "{}") {
+				return fail("bad wildcard segment '%s' (must start with '{' and end with '}')", seg)
+			}
+			if strings.ContainsAny(seg, ":*") {
+				return fail("literal ':' or '*' in segment '%s' cannot be represented", seg)
+			}
+			b.WriteString(seg)
+			if last {
+				break
+			}
+			continue
+		}
+		if !strings.HasSuffix(seg, "}") {
+			return fail("bad wildcard segment '%s' (must start with '{' and end with '}')", seg)
+		}
+		name := seg[1 : len(seg)-1]
+		if name == "$" {
+			if !last {
+				return fail("{$} not at end")
+			}
+			// {$}只匹配以'/'结尾的路径本身
+			break
+		}
+		multi := strings.HasSuffix(name, "...")
+		if multi {
+			name = name[:len(name)-3]
+			if !last {
+				return fail("{...} wildcard not at end")
+			}
+		}
+		if !isIdentifier(name) {
+			return fail("bad wildcard name '%s'", name)
+		}
+		if seen[name] {
+			return fail("duplicate wildcard name '%s'", name)
+		}
+		seen[name] = true
+		if multi {
+			b.WriteString("*" + name)
+			break
+		}
+		b.WriteString(":" + name)
+		if last {
+			break
+		}
+	}
+	return method, b.String(), nil
+}
+
+// isIdentifier
+// 判断s是否是一个合法的Go标识符,通配符的名称必须满足该要求
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		if !unicode.IsLetter(c) && c != '_' && (i == 0 || !unicode.IsDigit(c)) {
+			return false
+		}
+	}
+	return true
+}
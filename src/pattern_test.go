@@ -0,0 +1,56 @@
+package httprouter
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandlePattern(t *testing.T) {
+	r := New()
+	var got string
+	handler := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			got = name + " " + req.PathValue("id") + req.PathValue("path")
+		})
+	}
+	for pattern, name := range map[string]string{
+		"GET /items/{id}":  "item",
+		"/files/{path...}": "files",
+		"POST /items/{$}":  "create",
+	} {
+		if err := r.HandlePattern(pattern, handler(name)); err != nil {
+			t.Fatalf("HandlePattern(%q): %v", pattern, err)
+		}
+	}
+	for _, c := range []struct{ method, path, want string }{
+		{"GET", "/items/42", "item 42"},
+		{"DELETE", "/files/a/b", "files a/b"},
+		{"POST", "/items/", "create "},
+	} {
+		got = ""
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(c.method, c.path, nil))
+		if got != c.want {
+			t.Errorf("%s %s: got %q, want %q", c.method, c.path, got, c.want)
+		}
+	}
+}
+
+func TestHandlePatternErrors(t *testing.T) {
+	r := New()
+	if err := r.HandlePattern("GET /items/{id}", http.NotFoundHandler()); err != nil {
+		t.Fatal(err)
+	}
+	// ServeMux接受的组合,但是词典树无法表示
+	err := r.HandlePattern("GET /items/new", http.NotFoundHandler())
+	var ce *RouteConflictError
+	if !errors.As(err, &ce) || ce.Reason != ConflictWildcard || ce.Existing != "/items/:id" {
+		t.Errorf("conflicting pattern: got %v, want wildcard conflict with /items/:id", err)
+	}
+	for _, pattern := range []string{"", "GET items", "example.com/x", "/a/{b}c", "/{x}/{x}", "/{p...}/x", "/a:b"} {
+		if err := r.HandlePattern(pattern, http.NotFoundHandler()); err == nil {
+			t.Errorf("HandlePattern(%q) succeeded", pattern)
+		}
+	}
+}
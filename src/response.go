@@ -0,0 +1,283 @@
+package httprouter
+
+/*
+	responseWriter
+		WriteHeader
+		Write
+		flush
+		hijack
+		readFrom
+		Unwrap
+		core
+	flusher
+		Flush
+	hijacker
+		Hijack
+	readerFrom
+		ReadFrom
+	wrapWriter
+	HeaderWritten
+	headResponseWriter
+		WriteHeader
+		Write
+		Flush
+		Unwrap
+		finish
+		writeHeader
+*/
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// responseWriter
+// 包装http.ResponseWriter,记录响应是否已经开始以及状态码
+type responseWriter struct {
+	http.ResponseWriter
+	status  int
+	written bool
+}
+
+// WriteHeader
+// 记录状态码,1xx信息响应不算作响应已经开始
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.written && code >= 200 {
+		w.status = code
+		w.written = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write
+// 第一次写入响应体时,响应以200状态码开始
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if !w.written {
+		w.status = http.StatusOK
+		w.written = true
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// flush
+// 刷新会使响应开始,只有底层的ResponseWriter实现了http.Flusher时才会被调用
+func (w *responseWriter) flush() {
+	if !w.written {
+		w.status = http.StatusOK
+		w.written = true
+	}
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+// hijack
+// 允许处理器接管连接,例如WebSocket,接管后响应被视为已经开始
+func (w *responseWriter) hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.written = true
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+// readFrom
+// 转发给底层的io.ReaderFrom,使io.Copy仍然可以使用sendfile等优化
+func (w *responseWriter) readFrom(src io.Reader) (int64, error) {
+	if !w.written {
+		w.status = http.StatusOK
+		w.written = true
+	}
+	return w.ResponseWriter.(io.ReaderFrom).ReadFrom(src)
+}
+
+// Unwrap
+// 供http.ResponseController获取底层的ResponseWriter
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// core
+// 返回记录状态的responseWriter,下面带有可选接口的包装都通过嵌入获得该方法
+func (w *responseWriter) core() *responseWriter {
+	return w
+}
+
+// flusher
+// 为responseWriter提供http.Flusher
+type flusher struct{ *responseWriter }
+
+// Flush
+// 实现http.Flusher接口
+func (f flusher) Flush() { f.flush() }
+
+// hijacker
+// 为responseWriter提供http.Hijacker
+type hijacker struct{ *responseWriter }
+
+// Hijack
+// 实现http.Hijacker接口
+func (h hijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) { return h.hijack() }
+
+// readerFrom
+// 为responseWriter提供io.ReaderFrom
+type readerFrom struct{ *responseWriter }
+
+// ReadFrom
+// 实现io.ReaderFrom接口
+func (r readerFrom) ReadFrom(src io.Reader) (int64, error) { return r.readFrom(src) }
+
+// wrapWriter
+// 用responseWriter包装w,已经包装过的不会再次包装
+// 包装后的ResponseWriter只实现w本身实现了的http.Flusher,http.Hijacker以及io.ReaderFrom,
+// 使处理器通过类型断言检测这些接口时得到与未包装时相同的结果
+func wrapWriter(w http.ResponseWriter) http.ResponseWriter {
+	if _, ok := w.(interface{ core() *responseWriter }); ok {
+		return w
+	}
+	rw := &responseWriter{ResponseWriter: w}
+	const (
+		canFlush = 1 << iota
+		canHijack
+		canReadFrom
+	)
+	mask := 0
+	if _, ok := w.(http.Flusher); ok {
+		mask |= canFlush
+	}
+	if _, ok := w.(http.Hijacker); ok {
+		mask |= canHijack
+	}
+	if _, ok := w.(io.ReaderFrom); ok {
+		mask |= canReadFrom
+	}
+	switch mask {
+	case canFlush:
+		return struct {
+			*responseWriter
+			http.Flusher
+		}{rw, flusher{rw}}
+	case canHijack:
+		return struct {
+			*responseWriter
+			http.Hijacker
+		}{rw, hijacker{rw}}
+	case canReadFrom:
+		return struct {
+			*responseWriter
+			io.ReaderFrom
+		}{rw, readerFrom{rw}}
+	case canFlush | canHijack:
+		return struct {
+			*responseWriter
+			http.Flusher
+			http.Hijacker
+		}{rw, flusher{rw}, hijacker{rw}}
+	case canFlush | canReadFrom:
+		return struct {
+			*responseWriter
+			http.Flusher
+			io.ReaderFrom
+		}{rw, flusher{rw}, readerFrom{rw}}
+	case canHijack | canReadFrom:
+		return struct {
+			*responseWriter
+			http.Hijacker
+			io.ReaderFrom
+		}{rw, hijacker{rw}, readerFrom{rw}}
+	case canFlush | canHijack | canReadFrom:
+		return struct {
+			*responseWriter
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+		}{rw, flusher{rw}, hijacker{rw}, readerFrom{rw}}
+	}
+	return rw
+}
+
+// HeaderWritten
+// 判断响应头是否已经被写入,此时不能再修改状态码与响应头
+// 只对路由包装过的ResponseWriter有效,例如ErrorHandler与PanicHandler收到的ResponseWriter,
+// 其他情况总是返回false
+func HeaderWritten(w http.ResponseWriter) bool {
+	for {
+		switch rw := w.(type) {
+		case interface{ core() *responseWriter }:
+			return rw.core().written
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = rw.Unwrap()
+		default:
+			return false
+		}
+	}
+}
+
+// headResponseWriter
+// 用GET处理器响应HEAD请求时包装http.ResponseWriter
+// 丢弃响应体,但是记录其长度,在处理器返回后补充Content-Length响应头
+type headResponseWriter struct {
+	http.ResponseWriter
+	code        int   //处理器设置的状态码
+	wroteHeader bool  //是否已经把响应头写到了底层的ResponseWriter
+	length      int64 //被丢弃的响应体的长度
+}
+
+// WriteHeader
+// 推迟写入状态码,直到处理器返回或者刷新时才能确定Content-Length
+func (w *headResponseWriter) WriteHeader(code int) {
+	if code >= 100 && code < 200 {
+		// 1xx信息响应可以被写入多次,直接交给底层处理
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+// Write
+// 丢弃响应体,只记录它的长度
+func (w *headResponseWriter) Write(p []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	w.length += int64(len(p))
+	return len(p), nil
+}
+
+// Flush
+// 刷新时必须先写入响应头,此时不再补充Content-Length
+func (w *headResponseWriter) Flush() {
+	w.writeHeader(false)
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap
+// 供http.ResponseController获取底层的ResponseWriter
+func (w *headResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish
+// 处理器返回后调用,写入状态码以及Content-Length
+func (w *headResponseWriter) finish() {
+	w.writeHeader(true)
+}
+
+// writeHeader
+// 把状态码写到底层的ResponseWriter,只会执行一次
+func (w *headResponseWriter) writeHeader(setLength bool) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	h := w.Header()
+	if setLength && h.Get("Content-Length") == "" && h.Get("Transfer-Encoding") == "" &&
+		w.code != http.StatusNoContent && w.code != http.StatusNotModified {
+		h.Set("Content-Length", strconv.FormatInt(w.length, 10))
+	}
+	w.ResponseWriter.WriteHeader(w.code)
+}
@@ -0,0 +1,99 @@
+package httprouter
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// plainWriter
+// 不实现任何可选接口的ResponseWriter
+type plainWriter struct {
+	http.ResponseWriter
+}
+
+// readFromWriter
+// 实现了io.ReaderFrom的ResponseWriter,记录ReadFrom是否被调用
+type readFromWriter struct {
+	http.ResponseWriter
+	readFrom bool
+}
+
+// ReadFrom
+// 实现io.ReaderFrom接口
+func (w *readFromWriter) ReadFrom(src io.Reader) (int64, error) {
+	w.readFrom = true
+	return io.Copy(w.ResponseWriter, src)
+}
+
+// optionalInterfaces
+// 返回w实现的可选接口
+func optionalInterfaces(w http.ResponseWriter) (flush, hijack, readFrom bool) {
+	_, flush = w.(http.Flusher)
+	_, hijack = w.(http.Hijacker)
+	_, readFrom = w.(io.ReaderFrom)
+	return
+}
+
+func TestWrapWriterOptionalInterfaces(t *testing.T) {
+	for _, w := range []http.ResponseWriter{
+		plainWriter{httptest.NewRecorder()},
+		httptest.NewRecorder(),
+		&readFromWriter{ResponseWriter: plainWriter{httptest.NewRecorder()}},
+		&readFromWriter{ResponseWriter: httptest.NewRecorder()},
+	} {
+		f, h, rf := optionalInterfaces(w)
+		wrapped := wrapWriter(w)
+		wf, wh, wrf := optionalInterfaces(wrapped)
+		if f != wf || h != wh || rf != wrf {
+			t.Errorf("%T: wrapped interfaces (flush %v, hijack %v, readFrom %v), want (%v, %v, %v)",
+				w, wf, wh, wrf, f, h, rf)
+		}
+		if wrapWriter(wrapped) != wrapped {
+			t.Errorf("%T: wrapped twice", w)
+		}
+	}
+}
+
+func TestWrapWriterTracksOptionalWrites(t *testing.T) {
+	w := wrapWriter(httptest.NewRecorder())
+	w.(http.Flusher).Flush()
+	if !HeaderWritten(w) {
+		t.Error("Flush did not mark the response as written")
+	}
+
+	rfw := &readFromWriter{ResponseWriter: httptest.NewRecorder()}
+	w = wrapWriter(rfw)
+	// 隐藏strings.Reader的WriteTo,使io.Copy调用ReadFrom
+	if _, err := io.Copy(w, struct{ io.Reader }{strings.NewReader("body")}); err != nil {
+		t.Fatal(err)
+	}
+	if !rfw.readFrom {
+		t.Error("io.Copy did not reach the underlying ReadFrom")
+	}
+	if !HeaderWritten(w) {
+		t.Error("ReadFrom did not mark the response as written")
+	}
+
+	// 底层不支持时,ResponseController应当报告http.ErrNotSupported
+	w = wrapWriter(plainWriter{httptest.NewRecorder()})
+	if err := http.NewResponseController(w).Flush(); !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("Flush on a plain writer returned %v, want ErrNotSupported", err)
+	}
+}
+
+func TestRouterKeepsOptionalInterfaces(t *testing.T) {
+	r := New()
+	r.PanicHandler = func(http.ResponseWriter, *http.Request, interface{}) {}
+	var flush, hijack bool
+	r.GET("/", func(w http.ResponseWriter, req *http.Request, _ Params) {
+		flush, hijack, _ = optionalInterfaces(w)
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if !flush || hijack {
+		t.Errorf("handler saw flush %v, hijack %v; want true, false", flush, hijack)
+	}
+}
@@ -2,6 +2,7 @@ package httprouter
 
 /*
 	paramsKey
+	allowedMethodsKey
 	Param
 	Params
 		ByName
@@ -15,20 +16,35 @@ package httprouter
 		PUT
 		PATCH
 		DELETE
+		Any
 		Handler
 		HandlerFunc
 		ServeFiles
 		recv
 		Lookup
+		lookupAny
+		lookupFallback
+		serveFallback
+		routePath
+		matchRoute
 		allowed
 		ServeHTTP
+		implements
+		redirect
+		redirectHEAD
+		handleNotFound
 
 	New
 	ParamsFromContext
+	AllowedMethodsFromContext
+	isToken
 */
 import (
 	"context"
+	"log/slog"
 	"net/http"
+	"runtime/debug"
+	"strings"
 )
 
 // 变量定义
@@ -39,11 +55,24 @@ var _ = New()
 // 仅Go1.7以上版本支持
 var ParamsKey = paramsKey{}
 
+// 自动的OPTIONS响应中,允许的请求方法列表以AllowedMethodsKey为键存储在请求的context中
+var AllowedMethodsKey = allowedMethodsKey{}
+
+// anyMethods
+// 通过Any注册的路径所允许的标准请求方法
+var anyMethods = []string{
+	"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "CONNECT", "OPTIONS", "TRACE",
+}
+
 // 结构体定义
 // paramsKey
 // 在URL的参数被存储时,以paramsKey作为键
 type paramsKey struct{}
 
+// allowedMethodsKey
+// 调用GlobalOPTIONS时,以allowedMethodsKey作为键存储允许的请求方法
+type allowedMethodsKey struct{}
+
 // Param
 // 是一个独立的URL参数,由一个key和一个value组成
 type Param struct {
@@ -77,6 +106,10 @@ type Handle func(http.ResponseWriter, *http.Request, Params)
 type Router struct {
 	trees map[string]*node
 
+	// 通过Any注册的,接收所有请求方法的路由
+	// 仅当请求方法对应的词典树中没有匹配的路由时才会被检索
+	anyTree *node
+
 	// 如果当前的路由不能匹配到一个路由器但存在一个与该路径后添加'/'的路径匹配的处理器,则自动重定向
 	// 例如:
 	// 		如果/foo/是请求路径但是仅存在一个匹配/foo的路由,
@@ -108,6 +141,26 @@ type Router struct {
 	// 经常性可选的处理器的优先级要高于自动响应
 	HandleOPTIONS bool
 
+	// 自动响应OPTIONS请求时,在设置了"Allow"响应头之后调用的http.Handler
+	// 如果未设置,将会返回一个空的200响应
+	// 允许的请求方法可以通过AllowedMethodsFromContext从请求的context中获取,
+	// 例如用来生成CORS响应头或者接口说明
+	GlobalOPTIONS http.Handler
+
+	// 如果为true,HEAD请求在没有匹配的HEAD路由时会交给对应的GET处理器处理
+	// 响应体会被丢弃,但是响应头以及Content-Length会被保留
+	// 同时只要允许GET,Allow请求头中也会包含HEAD
+	HandleHEAD bool
+
+	// 如果为true,POST请求可以通过X-HTTP-Method-Override请求头或者_method表单字段
+	// 指定另一个请求方法,供只能发送GET和POST的HTML表单以及代理使用
+	// 如果该方法存在匹配的路由,请求将会以该方法被处理,否则仍然作为POST请求处理
+	HandleMethodOverride bool
+
+	// 允许通过方法覆盖指定的请求方法
+	// 如果未设置,只允许PUT,PATCH以及DELETE;比较时不区分大小写
+	MethodOverrideMethods []string
+
 	// 当没有发现匹配的路径时执行的http.Handler
 	// 如果未设置,那么将会调用http.NotFound.
 	NotFound http.Handler
@@ -118,17 +171,82 @@ type Router struct {
 	// 在处理器被调用之前,先会设置一个附带允许的请求方法的"Allow"的请求头
 	MethodNotAllowed http.Handler
 
+	// 如果设置为true,当请求的方法在整个路由中都没有注册过任何路由时,
+	// 请求将会收到一段HTTP status为501的响应消息"Not Implemented",而不是405或者404
+	// 可以通过Any,HandleHEAD或者HandleOPTIONS处理的请求不受影响
+	HandleNotImplemented bool
+
+	// 当HandleNotImplemented设置为true并且请求的方法未被注册时调用的http.Handler
+	// 如果这个属性没有设置,一个包含了http.StatusNotImplemented信息的http.Error将会被调用
+	NotImplemented http.Handler
+
 	// 处理宕机并且从http handler恢复数据的功能
 	// 它被用来生成一个错误反馈页面并且返回一个为500的http error(Internal Server Error).
 	// 这个处理器可以避免你的服务器因为不能恢复的宕机而崩溃
+	// 通过PanicFromContext可以获取宕机的堆栈以及匹配到的路由,
+	// 通过HeaderWritten可以判断响应是否已经开始;LogPanics是一个内置的实现
 	PanicHandler func(http.ResponseWriter, *http.Request, interface{})
+
+	// 生成由路由自身产生的错误响应,包括404,405,501,宕机,没有执行的重定向,
+	// 以及ServeFiles与ServeFS中没有权限(403)或者读取失败(500)的静态文件
+	// 仅在对应的NotFound,MethodNotAllowed,NotImplemented或者PanicHandler未设置时使用
+	// 设置该选项后路由会从宕机中恢复,并且在生成500响应之前通过slog.Default()记录宕机的值与堆栈
+	// 如果未设置,则使用http.NotFound以及http.Error生成纯文本的响应
+	// ProblemJSON是一个内置的RFC 7807实现
+	ErrorRenderer func(http.ResponseWriter, *http.Request, *RouteError)
+
+	// 处理通过HandleE等方法注册的ErrorHandle返回的非nil错误
+	// 可以通过HeaderWritten判断处理器是否已经开始写入响应
+	// 如果未设置,在响应尚未开始时写入一个状态码由ErrorStatus决定的纯文本响应
+	ErrorHandler func(http.ResponseWriter, *http.Request, error)
+
+	// 如果为true,在分派请求时记录每个路由被请求的次数,由DebugHandler展示
+	// 计数使用实际处理请求的路由与请求方法,包括覆盖后的方法以及通过GET处理的HEAD请求
+	// 必须在开始处理请求之前设置
+	CountRouteHits bool
+
+	// 跨域资源共享的配置,如果未设置则不处理CORS
+	// 预检请求由自动的OPTIONS响应处理,因此需要同时设置HandleOPTIONS
+	// 通过Any注册的路由也是如此,它们不会收到配置了CORS的预检请求
+	// 可以通过SetCORS为单独的路由覆盖该配置
+	CORS *CORS
+
+	// 通过SetCORS为单独的路由设置的CORS配置,键为注册路由时使用的路径
+	corsRoutes map[string]*CORS
+
+	// 通过WebDAV注册的路由,键为注册路由时使用的路径
+	davRoutes map[string]bool
+
+	// 通过Fingerprint注册的静态文件清单,供AssetURL使用
+	assets []*assetManifest
+
+	// 服务器范围内允许的请求方法,注册路由时重新计算
+	globalAllowed string
+
+	// 每个注册时使用的路径所允许的请求方法,注册路由时更新
+	patternAllowed map[string]patternAllow
+
+	// 所有请求方法以及Any注册的路径组成的词典树,用来在一次检索中找到请求匹配的路径
+	// 不同的请求方法使用了互相冲突的路径时为nil,同时allowMixed为true
+	allowTree  *node
+	allowMixed bool
+
+	// 通过SetRouteInfo为路由设置的名称与元数据,键为"METHOD /path"
+	routeInfo map[string]RouteInfo
+
+	// 每个路由的请求计数,仅在设置了CountRouteHits时计数
+	stats routeStats
 }
 
 // Handle
 // Handle为给定的路径和方法注册了一个新的请求处理器
 // 对于GET,POST,PUT,PATCH以及DELETE的请求,都有各自的快捷方法可供调用
 // 这个方法可以在高负荷下正常使用,并且允许不频繁地,非标准化的私有的方法调用(例如在代理下的内部通信)
+// 路由与已有路由冲突时触发宕机,需要返回错误时使用TryHandle
 func (r *Router) Handle(method, path string, handle Handle) {
+	if !isToken(method) {
+		panic("method must be a valid HTTP token in method '" + method + "'")
+	}
 	if path[0] != '/' {
 		panic("path must begin with '/' in path '" + path + "'")
 	}
@@ -140,7 +258,10 @@ func (r *Router) Handle(method, path string, handle Handle) {
 		root = new(node)
 		r.trees[method] = root
 	}
-	root.addRoute(path, handle)
+	if err := root.addRoute(path, handle); err != nil {
+		panic(err.Error())
+	}
+	r.updateAllowed(method, path)
 }
 
 //GET
@@ -185,6 +306,23 @@ func (r *Router) DELETE(path string, handle Handle) {
 	r.Handle("DELETE", path, handle)
 }
 
+// Any
+// 为给定路径注册一个接收所有请求方法的处理器,例如代理或者JSON-RPC接口
+// 它被保存在一颗单独的词典树中,因此可以与同一路径上为某个方法注册的处理器共存,
+// 在ServeHTTP中,只有当请求方法对应的词典树中没有匹配的路由时,才会调用该处理器
+func (r *Router) Any(path string, handle Handle) {
+	if path[0] != '/' {
+		panic("path must begin with '/' in path '" + path + "'")
+	}
+	if r.anyTree == nil {
+		r.anyTree = new(node)
+	}
+	if err := r.anyTree.addRoute(path, handle); err != nil {
+		panic(err.Error())
+	}
+	r.updateAllowed("", path)
+}
+
 // Handler
 // 一个允许把http.Handler当做request handle来调用的适配器
 // 在Go1.7之后版本,ParamsKey下的Params在请求的context也是可用的
@@ -209,25 +347,50 @@ func (r *Router) HandlerFunc(method, path string, handler http.HandlerFunc) {
 // 路径必须以"/*filepath"结尾,文件都从本地路径/defined/root/dir/*filepath处获取
 // 例如:
 // 		如果根路径是"/etc"并且*filepath是"passwd",将会找到本地文件"/etc/passwd".
-// 本质上调用了一个http.FileServer,因此调用了http.NotFound而不是Router的NotFound处理器.
+// 文件不存在,或者目录中没有index.html时,调用Router的NotFound处理器.
+// 路由同时为GET和HEAD注册,OPTIONS请求以及405响应交给路由的自动处理.
 // 为了使用操作系统的文件系统实现,使用http.Dir:
 // 		router.ServeFiles("/src/*filepath", http.Dir("/var/www"))
-func (r *Router) ServeFiles(path string, root http.FileSystem) {
+// 可选项见FileOption
+func (r *Router) ServeFiles(path string, root http.FileSystem, opts ...FileOption) {
 	if len(path) < 10 || path[len(path)-10:] != "/*filepath" {
 		panic("path must end with /*filepath in path '" + path + "'")
 	}
-	fileServer := http.FileServer(root)
-	r.GET(path, func(w http.ResponseWriter, req *http.Request, ps Params) {
-		req.URL.Path = ps.ByName("filepath")
-		fileServer.ServeHTTP(w, req)
-	})
+	r.serveFiles(path, root, opts)
 }
 
 // recv
 // 遇到宕机时进行恢复
+// http.ErrAbortHandler表示有意中止响应,会被再次抛出交给net/http处理
+// 宕机的堆栈以及匹配到的路由以PanicKey为键存储在交给PanicHandler的请求的context中
+// 没有设置PanicHandler时,使用slog.Default()记录宕机的值与堆栈,
+// 如果响应尚未开始,再把宕机交给ErrorRenderer生成500响应
 func (r *Router) recv(w http.ResponseWriter, req *http.Request) {
 	if rcv := recover(); rcv != nil {
-		r.PanicHandler(w, req, rcv)
+		if rcv == http.ErrAbortHandler {
+			panic(rcv)
+		}
+		info := &PanicInfo{
+			Value: rcv,
+			Stack: debug.Stack(),
+			Route: r.routePath(req.Method, req.URL.Path),
+		}
+		if r.PanicHandler != nil {
+			ctx := context.WithValue(req.Context(), PanicKey, info)
+			r.PanicHandler(w, req.WithContext(ctx), rcv)
+			return
+		}
+		// 恢复宕机后net/http不会再记录它,因此由路由记录
+		logPanic(slog.Default(), req, info)
+		if HeaderWritten(w) {
+			// 响应已经开始,无法再写入500响应
+			return
+		}
+		r.renderError(w, req, &RouteError{
+			Err:       ErrPanic,
+			Status:    http.StatusInternalServerError,
+			Recovered: rcv,
+		})
 	}
 }
 
@@ -236,46 +399,113 @@ func (r *Router) recv(w http.ResponseWriter, req *http.Request) {
 // 这是一个围绕路由去建立框架的有用案例.
 // 如果该路径被找到了,返回这个处理器函数和路径的参数值.
 // 否则第三个返回值表明是否重定向到相同的头部包含'/'的路径
+// 与ServeHTTP一致,该方法没有匹配的路由时会检索通过Any注册的路由
 func (r *Router) Lookup(method, path string) (Handle, Params, bool) {
+	var tsr bool
 	if root := r.trees[method]; root != nil {
-		return root.getValue(path)
+		var handle Handle
+		var ps Params
+		if handle, ps, tsr = root.getValue(path); handle != nil {
+			return handle, ps, false
+		}
 	}
-	return nil, nil, false
+	if handle, ps, _, _ := r.lookupFallback(method, path); handle != nil {
+		return handle, ps, false
+	}
+	return nil, nil, tsr
 }
 
-// allowed
-func (r *Router) allowed(path, reqMethod string) (allow string) {
-	if path == "*" { //服务器范围
-		for method := range r.trees {
-			if method == "OPTIONS" {
-				continue
-			}
-			//把请求的方法添加到允许的方法列表中去
-			if len(allow) == 0 {
-				allow = method
-			} else {
-				allow += "," + method
+// lookupAny
+// 在通过Any注册的路由中检索给定的路径
+func (r *Router) lookupAny(path string) (Handle, Params) {
+	if r.anyTree == nil {
+		return nil, nil
+	}
+	handle, ps, _ := r.anyTree.getValue(path)
+	return handle, ps
+}
+
+// lookupFallback
+// 请求的方法对应的词典树中没有匹配的路由时,依次检索:
+// 		1.如果设置了HandleHEAD,HEAD请求检索GET的词典树,并丢弃响应体
+// 		2.通过Any注册的路由
+// 同时返回匹配到的路由注册时使用的请求方法与路径,通过Any注册的路由的请求方法为空字符串
+func (r *Router) lookupFallback(method, path string) (Handle, Params, string, string) {
+	if method == "HEAD" && r.HandleHEAD {
+		if root := r.trees["GET"]; root != nil {
+			if leaf, ps, _ := root.getNode(path, nil); leaf != nil {
+				return func(w http.ResponseWriter, req *http.Request, ps Params) {
+					hw := &headResponseWriter{ResponseWriter: w}
+					leaf.handle(hw, req, ps)
+					hw.finish()
+				}, ps, "GET", leaf.fullPath
 			}
 		}
-	} else { //特别的路径
-		for method := range r.trees {
-			// 跳过请求的方法,我们已经尝试过这一个了
-			if method == reqMethod || method == "OPTIONS" {
-				continue
-			}
-			handle, _, _ := r.trees[method].getValue(path)
-			if handle != nil {
-				//把请求的方法添加到允许的方法列表中去
-				if len(allow) == 0 {
-					allow = method
-				} else {
-					allow += "," + method
-				}
-			}
+	}
+	if r.anyTree != nil {
+		if leaf, ps, _ := r.anyTree.getNode(path, nil); leaf != nil {
+			return leaf.handle, ps, "", leaf.fullPath
 		}
 	}
-	if len(allow) > 0 {
-		allow += ",OPTIONS"
+	return nil, nil, "", ""
+}
+
+// serveFallback
+// ServeHTTP使用的lookupFallback
+// 配置了CORS的预检请求不会交给通过Any注册的路由,而是由自动的OPTIONS响应处理
+func (r *Router) serveFallback(req *http.Request) (Handle, Params, string, string) {
+	path := req.URL.Path
+	if r.HandleOPTIONS && isPreflight(req) &&
+		r.corsFor(req.Header.Get("Access-Control-Request-Method"), path) != nil {
+		return nil, nil, "", ""
+	}
+	return r.lookupFallback(req.Method, path)
+}
+
+// routePath
+// 返回请求会匹配到的路由在注册时使用的完整路径,检索顺序与ServeHTTP一致
+// 没有匹配的路由时返回空字符串
+func (r *Router) routePath(method, path string) string {
+	_, route := r.matchRoute(method, path)
+	return route
+}
+
+// matchRoute
+// 与routePath相同,同时返回路由注册时使用的请求方法
+// 通过Any注册的路由的请求方法为空字符串
+func (r *Router) matchRoute(method, path string) (tree, route string) {
+	if root := r.trees[method]; root != nil {
+		if leaf, _, _ := root.getNode(path, nil); leaf != nil {
+			return method, leaf.fullPath
+		}
+	}
+	if root := r.trees["GET"]; root != nil && method == "HEAD" && r.HandleHEAD {
+		if leaf, _, _ := root.getNode(path, nil); leaf != nil {
+			return "GET", leaf.fullPath
+		}
+	}
+	if r.anyTree != nil {
+		if leaf, _, _ := r.anyTree.getNode(path, nil); leaf != nil {
+			return "", leaf.fullPath
+		}
+	}
+	return "", ""
+}
+
+// allowed
+// 返回给定路径所允许的请求方法,按照规范的顺序以','连接,OPTIONS总是在最后
+// path为"*"时返回服务器范围内允许的方法
+// 服务器范围的结果以及每个注册时使用的路径的结果都在注册路由时预先计算,
+// 处理请求时通常只需在所有路径组成的一颗词典树中检索一次,详见pathAllowed
+func (r *Router) allowed(path string) (allow string) {
+	if path == "*" { //服务器范围
+		allow = r.globalAllowed
+	} else { //特别的路径
+		allow = r.pathAllowed(path)
+	}
+	// GET的路由同时处理HEAD请求,规范的顺序中HEAD紧跟在GET之后
+	if r.HandleHEAD && strings.HasPrefix(allow, "GET,") && !strings.HasPrefix(allow, "GET,HEAD,") {
+		allow = "GET,HEAD," + allow[len("GET,"):]
 	}
 	return
 }
@@ -283,72 +513,191 @@ func (r *Router) allowed(path, reqMethod string) (allow string) {
 // ServeHTTP
 // 使Router实现http.Handle接口
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	if r.PanicHandler != nil {
+	if r.PanicHandler != nil || r.ErrorRenderer != nil {
+		// 包装ResponseWriter,宕机时可以判断响应是否已经开始
+		w = wrapWriter(w)
 		defer r.recv(w, req)
 	}
+	if req.Header.Get("Origin") != "" && !isPreflight(req) {
+		// 为跨域的请求添加CORS响应头
+		if c := r.corsFor(req.Method, req.URL.Path); c != nil {
+			c.decorate(w, req)
+		}
+	}
+	if req.Method == "POST" && r.HandleMethodOverride {
+		// 优先使用覆盖后的方法对应的路由
+		if leaf, ps := r.lookupOverride(req); leaf != nil {
+			r.countHit(req.Method, leaf.fullPath)
+			leaf.handle(w, req, ps)
+			return
+		}
+	}
 	path := req.URL.Path
-	if root := r.trees[req.Method]; root != nil {
-		if handle, ps, tsr := root.getValue(path); handle != nil {
-			handle(w, req, ps)
+	root := r.trees[req.Method]
+	var tsr bool
+	if root != nil {
+		var leaf *node
+		var ps Params
+		if leaf, ps, tsr = root.getNode(path, nil); leaf != nil {
+			r.countHit(req.Method, leaf.fullPath)
+			leaf.handle(w, req, ps)
 			return
-		} else if req.Method != "CONNECT" && path != "/" {
-			code := 301 //GET请求,永久重定向
-			if req.Method != "GET" {
-				//相同方法,临时重定向
-				//在Go1.3版本,不支持308状态码
-				code = 307
-			}
-			if tsr && r.RedirectTrailingSlash {
-				if len(path) > 1 && path[len(path)-1] == '/' {
-					req.URL.Path = path[:len(path)-1]
-				} else {
-					req.URL.Path = path + "/"
-				}
-				http.Redirect(w, req, req.URL.String(), code)
-				return
-			}
-			// 尝试去修正请求路径
-			if r.RedirectFixedPath {
-				fixedPath, found := root.findCaseInsensitivePath(
-					CleanPath(path), r.RedirectTrailingSlash,
-				)
-				if found {
-					req.URL.Path = string(fixedPath)
-					http.Redirect(w, req, req.URL.String(), code)
-					return
-				}
-			}
 		}
 	}
+	if handle, ps, tree, route := r.serveFallback(req); handle != nil {
+		// 回退到GET或者通过Any注册的路由
+		r.countHit(tree, route)
+		handle(w, req, ps)
+		return
+	}
+	r.countHit("", "")
+	if (root != nil && r.redirect(w, req, root, tsr)) || r.redirectHEAD(w, req) {
+		return
+	}
+	if r.HandleNotImplemented && !r.implements(req.Method) {
+		// 处理501响应状态码
+		if r.NotImplemented != nil {
+			r.NotImplemented.ServeHTTP(w, req)
+		} else {
+			r.renderError(w, req, &RouteError{
+				Err:    ErrNotImplemented,
+				Status: http.StatusNotImplemented,
+			})
+		}
+		return
+	}
 	if req.Method == "OPTIONS" && r.HandleOPTIONS {
 		// 处理OPTIONS请求
-		if allow := r.allowed(path, req.Method); len(allow) > 0 {
+		if allow := r.allowed(path); len(allow) > 0 {
 			w.Header().Set("Allow", allow)
+			if r.davRoutes != nil && r.davRoutes[r.routePath("PROPFIND", path)] {
+				// 告知客户端该路径支持WebDAV
+				w.Header().Set("DAV", "1, 2")
+				w.Header().Set("MS-Author-Via", "DAV")
+			}
+			if isPreflight(req) {
+				// 使用为该路径实际注册的方法响应CORS预检请求
+				if c := r.corsFor(req.Header.Get("Access-Control-Request-Method"), path); c != nil {
+					c.preflight(w, req, allow)
+				}
+			}
+			if r.GlobalOPTIONS != nil {
+				ctx := context.WithValue(req.Context(), AllowedMethodsKey, strings.Split(allow, ","))
+				r.GlobalOPTIONS.ServeHTTP(w, req.WithContext(ctx))
+			}
 			return
 		}
 	} else {
 		// 处理405响应状态码
 		if r.HandleMethodNotAllowed {
-			if allow := r.allowed(path, req.Method); len(allow) > 0 {
+			if allow := r.allowed(path); len(allow) > 0 {
 				w.Header().Set("Allow", allow)
 				if r.MethodNotAllowed != nil {
 					r.MethodNotAllowed.ServeHTTP(w, req)
 				} else {
-					http.Error(w,
-						http.StatusText(http.StatusMethodNotAllowed),
-						http.StatusMethodNotAllowed,
-					)
+					r.renderError(w, req, &RouteError{
+						Err:     ErrMethodNotAllowed,
+						Status:  http.StatusMethodNotAllowed,
+						Allowed: strings.Split(allow, ","),
+					})
 				}
 				return
 			}
 		}
 	}
 	// 处理404响应状态码
+	r.handleNotFound(w, req)
+}
+
+// implements
+// 判断路由中是否有任何路由可以处理给定的请求方法
+func (r *Router) implements(method string) bool {
+	if _, ok := r.trees[method]; ok {
+		return true
+	}
+	switch method {
+	case "HEAD":
+		return r.HandleHEAD && r.trees["GET"] != nil
+	case "OPTIONS":
+		return r.HandleOPTIONS
+	}
+	return false
+}
+
+// redirect
+// 请求的方法对应的词典树中没有匹配的路由时,尝试重定向到添加或者去掉'/'的路径,或者修正过的路径
+// 如果已经重定向则返回true
+func (r *Router) redirect(w http.ResponseWriter, req *http.Request, root *node, tsr bool) bool {
+	path := req.URL.Path
+	if req.Method == "CONNECT" || path == "/" {
+		return false
+	}
+	code := 301 //GET请求,永久重定向
+	if req.Method != "GET" {
+		//相同方法,临时重定向
+		//在Go1.3版本,不支持308状态码
+		code = 307
+	}
+	if tsr && r.RedirectTrailingSlash {
+		if len(path) > 1 && path[len(path)-1] == '/' {
+			req.URL.Path = path[:len(path)-1]
+		} else {
+			req.URL.Path = path + "/"
+		}
+		http.Redirect(w, req, req.URL.String(), code)
+		return true
+	}
+	// 尝试去修正请求路径
+	if r.RedirectFixedPath {
+		fixedPath, found := root.findCaseInsensitivePath(
+			CleanPath(path), r.RedirectTrailingSlash,
+		)
+		if found {
+			req.URL.Path = string(fixedPath)
+			http.Redirect(w, req, req.URL.String(), code)
+			return true
+		}
+	}
+	return false
+}
+
+// redirectHEAD
+// 设置了HandleHEAD时,HEAD请求使用GET的词典树修正路径
+// HEAD的词典树(例如ServeFiles注册的路由)无法重定向时,GET的路由仍然可以被重定向到
+func (r *Router) redirectHEAD(w http.ResponseWriter, req *http.Request) bool {
+	if req.Method != "HEAD" || !r.HandleHEAD {
+		return false
+	}
+	root := r.trees["GET"]
+	if root == nil {
+		return false
+	}
+	_, _, tsr := root.getValue(req.URL.Path)
+	return r.redirect(w, req, root, tsr)
+}
+
+// handleNotFound
+// 调用NotFound处理器,如果未设置则交给ErrorRenderer或者调用http.NotFound
+// 如果存在一个添加或者去掉'/'后可以匹配的路径,但是没有重定向过去,
+// 交给ErrorRenderer的错误为ErrRedirectRefused
+func (r *Router) handleNotFound(w http.ResponseWriter, req *http.Request) {
 	if r.NotFound != nil {
 		r.NotFound.ServeHTTP(w, req)
-	} else {
-		http.NotFound(w, req)
+		return
+	}
+	e := &RouteError{Err: ErrNotFound, Status: http.StatusNotFound}
+	if r.ErrorRenderer != nil {
+		path := req.URL.Path
+		if _, _, tsr := r.Lookup(req.Method, path); tsr {
+			e.Err = ErrRedirectRefused
+			if len(path) > 1 && path[len(path)-1] == '/' {
+				e.Location = path[:len(path)-1]
+			} else {
+				e.Location = path + "/"
+			}
+		}
 	}
+	r.renderError(w, req, e)
 }
 
 // New
@@ -370,3 +719,26 @@ func ParamsFromContext(ctx context.Context) Params {
 	p, _ := ctx.Value(ParamsKey).(Params)
 	return p
 }
+
+// AllowedMethodsFromContext
+// 从请求的context中提取自动的OPTIONS响应所允许的请求方法,如果当前没有则返回nil
+// 仅在GlobalOPTIONS中可用
+func AllowedMethodsFromContext(ctx context.Context) []string {
+	methods, _ := ctx.Value(AllowedMethodsKey).([]string)
+	return methods
+}
+
+// isToken
+// 判断s是否是RFC 7230中定义的token,请求方法必须是一个token
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 0x7f || c <= ' ' || strings.IndexByte("\"(),/:;<=>?@[\\]{}", c) >= 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestHeadRedirectsThroughGETTree(t *testing.T) {
+	for _, withStatic := range []bool{false, true} {
+		r := New()
+		r.HandleHEAD = true
+		r.GET("/hello", fakeHandle)
+		if withStatic {
+			// ServeFS同时注册HEAD的路由,HEAD请求不再只依赖GET的词典树
+			r.ServeFS("/static/*p", fstest.MapFS{"a.txt": {Data: []byte("a")}})
+		}
+		for _, c := range []struct {
+			path     string
+			decision Decision
+		}{
+			{"/hello/", DecisionTrailingSlashRedirect},
+			{"/HELLO", DecisionFixedPathRedirect},
+		} {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("HEAD", c.path, nil))
+			if w.Code != http.StatusTemporaryRedirect || w.Header().Get("Location") != "/hello" {
+				t.Errorf("static %v, HEAD %s: got %d to %q, want 307 to /hello",
+					withStatic, c.path, w.Code, w.Header().Get("Location"))
+			}
+			if trace := r.Explain("HEAD", c.path); trace.Decision != c.decision || trace.Location != "/hello" {
+				t.Errorf("static %v, HEAD %s: Explain decided %s to %q, want %s to /hello",
+					withStatic, c.path, trace.Decision, trace.Location, c.decision)
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package httprouter
+
+/*
+	immutableCacheControl
+	SPA
+	spaConfig
+		fallbackFor
+		setCacheControl
+	ImmutableFiles
+*/
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// immutableCacheControl
+// 内容永远不会改变的文件所使用的Cache-Control响应头
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// SPA
+// 单页应用模式:请求的文件不存在时,提供fallback指定的文件(相对于根目录,通常是"index.html"),
+// 由前端的路由处理该路径,真实存在的文件仍然正常提供
+// 请求路径以exclude中任意一个前缀开头时不回退,仍然调用NotFound处理器,例如"/app/api/"
+// 回退文件设置"Cache-Control: no-cache";其他文件不会根据文件名被猜测为不可变,
+// 需要长期缓存的文件通过Fingerprint或者ImmutableFiles指定
+func SPA(fallback string, exclude ...string) FileOption {
+	return func(s *fileServer) {
+		s.spa = &spaConfig{
+			fallback: path.Clean("/" + fallback),
+			exclude:  exclude,
+		}
+	}
+}
+
+// spaConfig
+// 单页应用模式的配置
+type spaConfig struct {
+	fallback string   //回退文件,相对于根目录
+	exclude  []string //不回退的请求路径前缀
+}
+
+// fallbackFor
+// 判断给定的请求路径在文件不存在时是否应该回退
+func (c *spaConfig) fallbackFor(urlPath string) bool {
+	for _, prefix := range c.exclude {
+		if strings.HasPrefix(urlPath, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// setCacheControl
+// 为回退文件设置Cache-Control响应头
+func (c *spaConfig) setCacheControl(header http.Header, name string) {
+	if name == c.fallback {
+		// 回退文件引用了带有哈希值的文件,每次都需要重新验证
+		header.Set("Cache-Control", "no-cache")
+	}
+}
+
+// ImmutableFiles
+// 把文件名匹配任意一个pattern的文件作为不可变的文件长期缓存,pattern的语法与CacheControl相同
+// 用于构建工具生成的带有哈希值的文件,例如:
+// 		router.ServeFS("/*filepath", dist, httprouter.SPA("index.html"),
+// 			httprouter.ImmutableFiles("/assets/*"))
+func ImmutableFiles(patterns ...string) FileOption {
+	opts := make([]FileOption, len(patterns))
+	for i, pattern := range patterns {
+		opts[i] = CacheControl(pattern, immutableCacheControl)
+	}
+	return func(s *fileServer) {
+		for _, opt := range opts {
+			opt(s)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSPA(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":            {Data: []byte("index")},
+		"app.3f9a1c.js":         {Data: []byte("app")},
+		"report-202401.pdf":     {Data: []byte("report")},
+		"assets/main.8c2e41.js": {Data: []byte("main")},
+	}
+	for _, c := range []struct {
+		name   string
+		opts   []FileOption
+		path   string
+		status int
+		body   string
+		cache  string
+	}{
+		{name: "fallback", path: "/app/users/42", status: 200, body: "index", cache: "no-cache"},
+		{name: "fallback file itself", path: "/app/index.html", status: 200, body: "index", cache: "no-cache"},
+		{name: "root directory", path: "/app/", status: 200, body: "index", cache: "no-cache"},
+		{name: "existing file", path: "/app/app.3f9a1c.js", status: 200, body: "app"},
+		{name: "hex-like name is not guessed immutable", path: "/app/report-202401.pdf", status: 200, body: "report"},
+		{name: "excluded prefix", path: "/app/api/users", status: 404},
+		{name: "excluded prefix exact", path: "/app/api/", status: 404},
+		{name: "prefix only matches at start", path: "/app/v1/api/users", status: 200, body: "index", cache: "no-cache"},
+		{
+			name: "immutable by file name", opts: []FileOption{ImmutableFiles("app.*.js")},
+			path: "/app/app.3f9a1c.js", status: 200, body: "app", cache: immutableCacheControl,
+		},
+		{
+			name: "immutable by directory", opts: []FileOption{ImmutableFiles("/assets/*")},
+			path: "/app/assets/main.8c2e41.js", status: 200, body: "main", cache: immutableCacheControl,
+		},
+		{
+			name: "immutable pattern does not match", opts: []FileOption{ImmutableFiles("/assets/*")},
+			path: "/app/report-202401.pdf", status: 200, body: "report",
+		},
+	} {
+		r := New()
+		opts := append([]FileOption{SPA("index.html", "/app/api/")}, c.opts...)
+		r.ServeFS("/app/*filepath", fsys, opts...)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", c.path, nil))
+		if w.Code != c.status {
+			t.Errorf("%s: GET %s: status %d, want %d", c.name, c.path, w.Code, c.status)
+			continue
+		}
+		if c.status != http.StatusOK {
+			continue
+		}
+		if w.Body.String() != c.body {
+			t.Errorf("%s: GET %s: body %q, want %q", c.name, c.path, w.Body.String(), c.body)
+		}
+		if got := w.Header().Get("Cache-Control"); got != c.cache {
+			t.Errorf("%s: GET %s: Cache-Control %q, want %q", c.name, c.path, got, c.cache)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package httprouter
 
 /*
 	nodeType
+		String
 	node
 
 	incrementChildPrio
@@ -9,7 +10,12 @@ package httprouter
 		min
 		countParams
 	insertChild
+	clonePath
+	firstFullPath
+	eachRoute
 	getValue
+	tracer
+	getNode
 	findCaseInsensitivePath
 	findCaseInsensitivePathRec
 		min
@@ -32,6 +38,22 @@ const (
 //nodeType 类型
 type nodeType uint8
 
+// String方法
+// 返回节点类型的名称
+func (t nodeType) String() string {
+	switch t {
+	case static:
+		return "static"
+	case root:
+		return "root"
+	case param:
+		return "param"
+	case catchAll:
+		return "catchAll"
+	}
+	return "invalid"
+}
+
 // node类型
 // 解惑???????????????
 type node struct {
@@ -43,6 +65,7 @@ type node struct {
 	children  []*node
 	handle    Handle
 	priority  uint32 //优先权,包括本身在内地层级数目
+	fullPath  string //注册handle时使用的完整路径,仅在handle不为空时有效
 }
 
 // incrementChildPrio方法，增加给出索引对应的子节点的优先权，
@@ -68,8 +91,9 @@ func (n *node) incrementChildPrio(pos int) int {
 }
 
 // addRoute方法，把给定的handle与path关联起来
+// 路径与已有路由冲突或者不合法时返回一个RouteConflictError,此时词典树可能已经被部分修改
 // 并发情况下不安全！
-func (n *node) addRoute(path string, handle Handle) {
+func (n *node) addRoute(path string, handle Handle) *RouteConflictError {
 	// 优先权增加（路径越长，节点下路由越多越靠前、越优先）
 	fullPath := path
 	// 目录层级数目
@@ -99,6 +123,7 @@ func (n *node) addRoute(path string, handle Handle) {
 					indices:   n.indices,  //?
 					children:  n.children, //?
 					handle:    n.handle,   //?
+					fullPath:  n.fullPath,
 					priority:  n.priority - 1,
 				}
 				// 给child的maxParams属性赋值
@@ -112,12 +137,13 @@ func (n *node) addRoute(path string, handle Handle) {
 				n.indices = string([]byte{n.path[i]}) //字符索引为单个字母
 				n.path = path[:i]                     //那之前部分的从此节点往下的词典树呢？
 				n.handle = nil                        //节点处不需设置handle
+				n.fullPath = ""
 				n.wildChild = false
 			}
 			// 使新节点成为这个节点的子节点
 			if i < len(path) {
 				//公共前缀可以再缩减
-				path := path[i:]
+				path = path[i:]
 				//
 				if n.wildChild {
 					n = n.children[0]
@@ -129,9 +155,29 @@ func (n *node) addRoute(path string, handle Handle) {
 					numParams--
 					// 检查通配符匹配是否准确
 					if len(path) >= len(n.path) && n.path == path[:len(n.path)] &&
+						// 全匹配节点之下不能再添加子节点
+						n.nType != catchAll &&
 						// 检查更长的通配符
-						len(n.path) >= len(path) || path[len(n.path)] == '/' {
+						(len(n.path) >= len(path) || path[len(n.path)] == '/') {
 						continue walk
+					} else if n.nType == catchAll && path == n.path {
+						return &RouteConflictError{
+							Path:     fullPath,
+							Existing: n.fullPath,
+							Reason:   ConflictDuplicate,
+							msg:      "a handle is already registered for path '" + fullPath + "'",
+						}
+					} else if n.nType == catchAll && strings.HasPrefix(path, n.path+"/") {
+						// 全匹配参数会匹配剩余的全部路径,其后的路由永远无法到达
+						return &RouteConflictError{
+							Path:     fullPath,
+							Existing: n.fullPath,
+							Reason:   ConflictCatchAllPosition,
+							msg: "'" + path[len(n.path):] +
+								"' in new path '" + fullPath +
+								"' is unreachable below catch-all '" + n.path +
+								"' of existing route '" + n.fullPath + "'",
+						}
 					} else {
 						// 通配符冲突
 						var pathSeg string
@@ -142,11 +188,16 @@ func (n *node) addRoute(path string, handle Handle) {
 							pathSeg = strings.SplitN(path, "/", 2)[0]
 						}
 						prefix := fullPath[:strings.Index(fullPath, pathSeg)] + n.path
-						panic("'" + pathSeg +
-							"' in new path '" + fullPath +
-							"' conflicts with existing wildcard '" + n.path +
-							"' in existing prefix '" + prefix +
-							"'")
+						return &RouteConflictError{
+							Path:     fullPath,
+							Existing: n.firstFullPath(),
+							Reason:   ConflictWildcard,
+							msg: "'" + pathSeg +
+								"' in new path '" + fullPath +
+								"' conflicts with existing wildcard '" + n.path +
+								"' in existing prefix '" + prefix +
+								"'",
+						}
 					}
 				}
 				c := path[0]
@@ -175,27 +226,35 @@ func (n *node) addRoute(path string, handle Handle) {
 					n = child
 				}
 
-				n.insertChild(numParams, path, fullPath, handle)
-				return
+				return n.insertChild(numParams, path, fullPath, handle)
 			} else if i == len(path) {
 				// 把节点加入路径
 				if n.handle != nil {
-					panic("a handle is already registered for path '" + fullPath + "'")
+					return &RouteConflictError{
+						Path:     fullPath,
+						Existing: n.fullPath,
+						Reason:   ConflictDuplicate,
+						msg:      "a handle is already registered for path '" + fullPath + "'",
+					}
 				}
 				n.handle = handle
+				n.fullPath = fullPath
 			}
-			return
+			return nil
 
 		}
-	} else {
-		// 空词典树
-		n.insertChild(numParams, path, fullPath, handle)
-		n.nType = root
 	}
+	// 空词典树
+	if err := n.insertChild(numParams, path, fullPath, handle); err != nil {
+		return err
+	}
+	n.nType = root
+	return nil
 }
 
 // insertChild方法，插入子节点
-func (n *node) insertChild(numParams uint8, path, fullPath string, handle Handle) {
+// 路径不合法或者与已有的子节点冲突时返回一个RouteConflictError
+func (n *node) insertChild(numParams uint8, path, fullPath string, handle Handle) *RouteConflictError {
 	var offset int //路径中已经处理的字节数
 	// 发现第一个通配符前面的前缀
 	for i, max := 0, len(path); numParams > 0; i++ {
@@ -210,20 +269,33 @@ func (n *node) insertChild(numParams uint8, path, fullPath string, handle Handle
 			switch path[end] {
 			// 通配符名字必须不包含':' 与 '*'
 			case ':', '*':
-				panic("only one wildcard per path segment is allowed, has: '" +
-					path[i:] + "' in path '" + fullPath + "'")
+				return &RouteConflictError{
+					Path:   fullPath,
+					Reason: ConflictWildcardSegment,
+					msg: "only one wildcard per path segment is allowed, has: '" +
+						path[i:] + "' in path '" + fullPath + "'",
+				}
 			default:
 				end++
 			}
 		}
 		// 检查当我们在此处插入这个通配符时这个node是否会产生无法到达的子节点
 		if len(n.children) > 0 {
-			panic("wildcard route '" + path[i:end] +
-				"' conflicts with existing children in path '" + fullPath + "'")
+			return &RouteConflictError{
+				Path:     fullPath,
+				Existing: n.firstFullPath(),
+				Reason:   ConflictWildcard,
+				msg: "wildcard route '" + path[i:end] +
+					"' conflicts with existing children in path '" + fullPath + "'",
+			}
 		}
 		// 检查通配符是否有一个名字,而不仅仅是':' 与 '*'两个单独的字符
 		if end-i < 2 {
-			panic("wildcards must be named with a non-empty name in path '" + fullPath + "'")
+			return &RouteConflictError{
+				Path:   fullPath,
+				Reason: ConflictUnnamedWildcard,
+				msg:    "wildcards must be named with a non-empty name in path '" + fullPath + "'",
+			}
 		}
 		if c == ':' {
 			// param 匹配
@@ -256,16 +328,29 @@ func (n *node) insertChild(numParams uint8, path, fullPath string, handle Handle
 		} else { //全匹配
 			// 不是在路径结束的位置
 			if end != max || numParams > 1 {
-				panic("catch-all routes are only allowed at the end of the path in path '" + fullPath + "'")
+				return &RouteConflictError{
+					Path:   fullPath,
+					Reason: ConflictCatchAllPosition,
+					msg:    "catch-all routes are only allowed at the end of the path in path '" + fullPath + "'",
+				}
 			}
 			// 根路径
 			if len(n.path) > 0 && n.path[len(n.path)-1] == '/' {
-				panic("catch-all conflicts with existing handle for the path segment root in path '" + fullPath + "'")
+				return &RouteConflictError{
+					Path:     fullPath,
+					Existing: n.firstFullPath(),
+					Reason:   ConflictWildcard,
+					msg:      "catch-all conflicts with existing handle for the path segment root in path '" + fullPath + "'",
+				}
 			}
 			//目前为'/'修正宽度为1
 			i--
 			if path[i] != '/' {
-				panic("no / before catch-all in path '" + fullPath + "'")
+				return &RouteConflictError{
+					Path:   fullPath,
+					Reason: ConflictCatchAllPosition,
+					msg:    "no / before catch-all in path '" + fullPath + "'",
+				}
 			}
 			n.path = path[offset:i]
 			//第一个节点:空路径全匹配
@@ -284,15 +369,76 @@ func (n *node) insertChild(numParams uint8, path, fullPath string, handle Handle
 				nType:     catchAll,
 				maxParams: 1,
 				handle:    handle,
+				fullPath:  fullPath,
 				priority:  1,
 			}
 			n.children = []*node{child}
-			return
+			return nil
 		}
 	}
 	//将剩余路径部分和句柄handle插入到链条中
 	n.path = path[offset:]
 	n.handle = handle
+	n.fullPath = fullPath
+	return nil
+}
+
+// clonePath方法
+// 复制addRoute注册path时会经过的节点,返回新的根节点,其他节点与原词典树共享
+// addRoute只修改它经过的节点以及这些节点的children,因此在返回的词典树上注册不会影响原词典树
+// 复制的节点数目与路径的深度成正比,而不是与词典树的大小成正比
+func (n *node) clonePath(path string) *node {
+	c := *n
+	c.children = append([]*node(nil), n.children...)
+	// 与addRoute相同的检索过程,找到下一个会被修改的子节点
+	i := 0
+	max := min(len(path), len(n.path))
+	for i < max && path[i] == n.path[i] {
+		i++
+	}
+	if i < len(n.path) || i == len(path) {
+		// 在该节点处分割或者结束
+		return &c
+	}
+	path = path[i:]
+	next := -1
+	if n.wildChild {
+		next = 0
+	} else if n.nType == param && path[0] == '/' && len(n.children) == 1 {
+		next = 0
+	} else {
+		next = strings.IndexByte(n.indices, path[0])
+	}
+	if next >= 0 {
+		c.children[next] = n.children[next].clonePath(path)
+	}
+	return &c
+}
+
+// firstFullPath方法
+// 返回以该节点为根的词典树中按照子节点顺序找到的第一个已注册的完整路径
+// 用于在冲突时指出与之冲突的已有路由,没有已注册的路由时返回空字符串
+func (n *node) firstFullPath() string {
+	if n.handle != nil {
+		return n.fullPath
+	}
+	for _, child := range n.children {
+		if p := child.firstFullPath(); p != "" {
+			return p
+		}
+	}
+	return ""
+}
+
+// eachRoute方法
+// 按照子节点顺序对以该节点为根的词典树中每一个注册了handle的节点调用fn
+func (n *node) eachRoute(fn func(*node)) {
+	if n.handle != nil {
+		fn(n)
+	}
+	for _, child := range n.children {
+		child.eachRoute(fn)
+	}
 }
 
 // getValue方法
@@ -300,6 +446,23 @@ func (n *node) insertChild(numParams uint8, path, fullPath string, handle Handle
 // 通配符的值被存储到了一个map中
 // 如果该路径没有对应的handle,但却有一个在其基础上尾部含有'/'的路径,建议重定向
 func (n *node) getValue(path string) (handle Handle, p Params, tsr bool) {
+	leaf, p, tsr := n.getNode(path, nil)
+	if leaf != nil {
+		handle = leaf.handle
+	}
+	return
+}
+
+// tracer
+// getNode经过每一个节点时的回调,remaining为到达该节点时尚未匹配的路径
+// 只有Explain会传入,ServeHTTP等检索时为nil
+type tracer func(n *node, remaining, format string, args ...interface{})
+
+// getNode方法
+// 与getValue相同,但是返回匹配到的节点,可以从中获取注册时使用的完整路径
+// 没有匹配到handle时leaf为nil
+// trace不为nil时,每一次判断都会通过trace记录下来
+func (n *node) getNode(path string, trace tracer) (leaf *node, p Params, tsr bool) {
 walk:
 	for {
 		if len(path) > len(n.path) {
@@ -310,6 +473,9 @@ walk:
 					c := path[0]
 					for i := 0; i < len(n.indices); i++ {
 						if c == n.indices[i] {
+							if trace != nil {
+								trace(n, n.path+path, "matched prefix, next byte %q found at index %d of indices %q", c, i, n.indices)
+							}
 							n = n.children[i]
 							continue walk
 						}
@@ -317,9 +483,15 @@ walk:
 					// 没有找到,
 					// 如果一个同网址的链条存在,我们可以建议重定向到相同的不包含'/'的网址
 					tsr = (path == "/" && n.handle != nil)
+					if trace != nil {
+						trace(n, n.path+path, "matched prefix, next byte %q not in indices %q (tsr=%v)", c, n.indices, tsr)
+					}
 					return
 				}
 				// 处理通配符子节点
+				if trace != nil {
+					trace(n, n.path+path, "matched prefix, descending into wildcard child")
+				}
 				n = n.children[0]
 				switch n.nType {
 				case param:
@@ -341,20 +513,32 @@ walk:
 					// 进一步深入
 					if end < len(path) {
 						if len(n.children) > 0 {
+							if trace != nil {
+								trace(n, path, "captured %s=%q, continuing with %q", n.path[1:], path[:end], path[end:])
+							}
 							path = path[end:]
 							n = n.children[0]
 							continue walk
 						}
 						//
 						tsr = (len(path) == end+1)
+						if trace != nil {
+							trace(n, path, "captured %s=%q, but %q remains and the node has no children (tsr=%v)", n.path[1:], path[:end], path[end:], tsr)
+						}
 						return
 					}
-					if handle = n.handle; handle != nil {
+					if n.handle != nil {
+						leaf = n
+						if trace != nil {
+							trace(n, path, "captured %s=%q, node has a handle", n.path[1:], path[:end])
+						}
 						return
 					} else if len(n.children) == 1 {
 						// 没有处理器,检查是否存在一个处理该路径上带'/'的处理器
-						n = n.children[0]
-						tsr = (n.path == "/" && n.handle != nil)
+						tsr = (n.children[0].path == "/" && n.children[0].handle != nil)
+					}
+					if trace != nil {
+						trace(n, path, "captured %s=%q, but the node has no handle (tsr=%v)", n.path[1:], path[:end], tsr)
 					}
 					return
 				case catchAll:
@@ -368,7 +552,12 @@ walk:
 					p = p[:i+1]
 					p[i].Key = n.path[2:]
 					p[i].Value = path
-					handle = n.handle
+					if n.handle != nil {
+						leaf = n
+					}
+					if trace != nil {
+						trace(n, path, "catch-all captured %s=%q", n.path[2:], path)
+					}
 					return
 				default:
 					panic("invalid node type")
@@ -377,22 +566,35 @@ walk:
 		} else if path == n.path {
 			// 或许我们又来到了已经包含handle处理器的节点
 			// 检查我们所找的节点是否已经有处理器
-			if handle = n.handle; handle != nil {
+			if n.handle != nil {
+				leaf = n
+				if trace != nil {
+					trace(n, path, "path fully matched, node has a handle")
+				}
 				return
 			}
 			if path == "/" && n.wildChild && n.nType != root {
 				tsr = true
+				if trace != nil {
+					trace(n, path, "path fully matched at '/' before a wildcard (tsr=true)")
+				}
 				return
 			}
 			// 没有处理器,检查是否存在一个处理该路径上带'/'的处理器
 			for i := 0; i < len(n.indices); i++ {
 				if n.indices[i] == '/' {
-					n = n.children[i]
-					tsr = (len(n.path) == 1 && n.handle != nil) ||
-						(n.nType == catchAll && n.children[0].handle != nil)
+					c := n.children[i]
+					tsr = (len(c.path) == 1 && c.handle != nil) ||
+						(c.nType == catchAll && c.children[0].handle != nil)
+					if trace != nil {
+						trace(n, path, "path fully matched but the node has no handle, checked child '/' (tsr=%v)", tsr)
+					}
 					return
 				}
 			}
+			if trace != nil {
+				trace(n, path, "path fully matched but the node has no handle")
+			}
 			return
 		}
 		// 没有找到,
@@ -400,6 +602,9 @@ walk:
 		tsr = (path == "/") ||
 			(len(n.path) == len(path)+1 && n.path[len(path)] == '/' &&
 				path == n.path[:len(n.path)-1] && n.handle != nil)
+		if trace != nil {
+			trace(n, path, "path does not match the node path (tsr=%v)", tsr)
+		}
 		return
 	}
 }
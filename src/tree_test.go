@@ -0,0 +1,146 @@
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// fakeHandle
+// 测试中使用的handle
+func fakeHandle(http.ResponseWriter, *http.Request, Params) {}
+
+// routeHandle
+// 返回一个把注册时使用的路径写入响应的handle,用来判断匹配到了哪一条路由
+func routeHandle(route string) Handle {
+	return func(w http.ResponseWriter, _ *http.Request, _ Params) {
+		w.Write([]byte(route))
+	}
+}
+
+// routeCase
+// 一次检索以及期望的结果,route为空字符串表示不应匹配
+type routeCase struct {
+	path   string
+	route  string
+	params Params
+	tsr    bool
+}
+
+// getRouter
+// 返回注册了给定GET路由的Router,任何一条触发宕机时测试失败
+func getRouter(t *testing.T, routes []string) *Router {
+	t.Helper()
+	r := New()
+	for _, route := range routes {
+		func() {
+			defer func() {
+				if rcv := recover(); rcv != nil {
+					t.Fatalf("registering %q panicked: %v", route, rcv)
+				}
+			}()
+			r.GET(route, routeHandle(route))
+		}()
+	}
+	return r
+}
+
+// checkRoutes
+// 检查每一次检索匹配到的路由,参数以及tsr
+func checkRoutes(t *testing.T, r *Router, cases []routeCase) {
+	t.Helper()
+	for _, c := range cases {
+		handle, ps, tsr := r.Lookup("GET", c.path)
+		route := ""
+		if handle != nil {
+			w := httptest.NewRecorder()
+			handle(w, nil, ps)
+			route = w.Body.String()
+		}
+		if route != c.route {
+			t.Errorf("%q: matched route %q, want %q", c.path, route, c.route)
+		}
+		if route != "" && !reflect.DeepEqual(ps, c.params) {
+			t.Errorf("%q: params %v, want %v", c.path, ps, c.params)
+		}
+		if route == "" && tsr != c.tsr {
+			t.Errorf("%q: tsr %v, want %v", c.path, tsr, c.tsr)
+		}
+	}
+}
+
+// getPanics
+// 判断注册GET路由时是否触发宕机
+func getPanics(r *Router, route string) (panicked bool) {
+	defer func() {
+		panicked = recover() != nil
+	}()
+	r.GET(route, fakeHandle)
+	return false
+}
+
+func TestTreeNestedWildcards(t *testing.T) {
+	r := getRouter(t, []string{
+		"/",
+		"/cmd/:tool/:sub",
+		"/cmd/:tool/",
+		"/src/*filepath",
+		"/search/",
+		"/search/:query",
+		"/user_:name",
+		"/user_:name/about",
+		"/files/:dir/*filepath",
+		"/doc/",
+		"/doc/go_faq.html",
+		"/doc/go1.html",
+		"/info/:user/public",
+		"/info/:user/project/:project",
+	})
+	checkRoutes(t, r, []routeCase{
+		{path: "/", route: "/"},
+		{path: "/cmd/test/", route: "/cmd/:tool/", params: Params{{"tool", "test"}}},
+		{path: "/cmd/test", tsr: true},
+		{path: "/cmd/test/3", route: "/cmd/:tool/:sub", params: Params{{"tool", "test"}, {"sub", "3"}}},
+		{path: "/src/", route: "/src/*filepath", params: Params{{"filepath", "/"}}},
+		{path: "/src/some/file.png", route: "/src/*filepath", params: Params{{"filepath", "/some/file.png"}}},
+		{path: "/search/", route: "/search/"},
+		{path: "/search/someth!ng+in+ünìcodé", route: "/search/:query", params: Params{{"query", "someth!ng+in+ünìcodé"}}},
+		{path: "/search/someth!ng+in+ünìcodé/", tsr: true},
+		{path: "/user_gopher", route: "/user_:name", params: Params{{"name", "gopher"}}},
+		{path: "/user_gopher/about", route: "/user_:name/about", params: Params{{"name", "gopher"}}},
+		{path: "/files/js/inc/framework.js", route: "/files/:dir/*filepath", params: Params{{"dir", "js"}, {"filepath", "/inc/framework.js"}}},
+		{path: "/doc", tsr: true},
+		{path: "/doc/go1.html", route: "/doc/go1.html"},
+		{path: "/info/gordon/public", route: "/info/:user/public", params: Params{{"user", "gordon"}}},
+		{path: "/info/gordon/project/go", route: "/info/:user/project/:project", params: Params{{"user", "gordon"}, {"project", "go"}}},
+	})
+}
+
+func TestTreeDuplicateBelowSplit(t *testing.T) {
+	r := getRouter(t, []string{
+		"/search/",
+		"/support",
+		"/users/:id",
+		"/users/:id/posts",
+		"/cmd/:tool/:sub",
+		"/cmd/:tool/",
+	})
+	// 每一条都位于已经被分割的节点之下,必须被识别为重复注册
+	for _, route := range []string{"/search/", "/support", "/users/:id", "/users/:id/posts", "/cmd/:tool/", "/cmd/:tool/:sub"} {
+		if !getPanics(r, route) {
+			t.Errorf("duplicate route %q was accepted", route)
+		}
+	}
+	// 与已有通配符冲突
+	for _, route := range []string{"/users/new", "/users/:name", "/cmd/:command/"} {
+		if !getPanics(r, route) {
+			t.Errorf("conflicting route %q was accepted", route)
+		}
+	}
+	checkRoutes(t, r, []routeCase{
+		{path: "/users/42", route: "/users/:id", params: Params{{"id", "42"}}},
+		{path: "/users/42/posts", route: "/users/:id/posts", params: Params{{"id", "42"}}},
+		{path: "/cmd/vet/", route: "/cmd/:tool/", params: Params{{"tool", "vet"}}},
+	})
+}
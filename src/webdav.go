@@ -0,0 +1,33 @@
+package httprouter
+
+/*
+	davMethods
+	Router
+		WebDAV
+*/
+import (
+	"net/http"
+)
+
+// davMethods
+// WebDAV处理器需要接收的请求方法,OPTIONS由路由自动响应
+var davMethods = []string{
+	"GET", "HEAD", "POST", "PUT", "DELETE",
+	"PROPFIND", "PROPPATCH", "MKCOL", "COPY", "MOVE", "LOCK", "UNLOCK",
+}
+
+// WebDAV
+// 把一个WebDAV处理器(例如golang.org/x/net/webdav.Handler)挂载到给定的路径上
+// 路径必须以一个全匹配参数结尾,例如"/dav/*filepath",davMethods中的所有方法都会被注册
+// 请求会原样交给处理器,处理器需要自己去掉路径前缀(webdav.Handler的Prefix字段)
+// 如果设置了HandleOPTIONS,自动的OPTIONS响应中会为这些路径添加DAV以及MS-Author-Via响应头
+func (r *Router) WebDAV(path string, handler http.Handler) {
+	catchAllParam(path)
+	for _, method := range davMethods {
+		r.Handler(method, path, handler)
+	}
+	if r.davRoutes == nil {
+		r.davRoutes = make(map[string]bool)
+	}
+	r.davRoutes[path] = true
+}